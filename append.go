@@ -0,0 +1,103 @@
+package gobin
+
+import "time"
+
+// The Append functions append the Safe encoding of a value to dst and return
+// the extended slice, growing it as needed, like strconv.AppendInt. They let
+// several values or messages be encoded into one buffer without computing
+// their sizes first.
+
+// AppendBool appends the Safe encoding of v to dst.
+func AppendBool(dst []byte, v bool) []byte {
+	return Safe{}.AppendBool(dst, v)
+}
+
+// AppendInt appends the Safe encoding of v to dst.
+func AppendInt(dst []byte, v int) []byte {
+	return Safe{}.AppendInt(dst, v)
+}
+
+// AppendInt8 appends the Safe encoding of v to dst.
+func AppendInt8(dst []byte, v int8) []byte {
+	return Safe{}.AppendInt8(dst, v)
+}
+
+// AppendInt16 appends the Safe encoding of v to dst.
+func AppendInt16(dst []byte, v int16) []byte {
+	return Safe{}.AppendInt16(dst, v)
+}
+
+// AppendInt32 appends the Safe encoding of v to dst.
+func AppendInt32(dst []byte, v int32) []byte {
+	return Safe{}.AppendInt32(dst, v)
+}
+
+// AppendInt64 appends the Safe encoding of v to dst.
+func AppendInt64(dst []byte, v int64) []byte {
+	return Safe{}.AppendInt64(dst, v)
+}
+
+// AppendUint appends the Safe encoding of v to dst.
+func AppendUint(dst []byte, v uint) []byte {
+	return Safe{}.AppendUint(dst, v)
+}
+
+// AppendUint8 appends the Safe encoding of v to dst.
+func AppendUint8(dst []byte, v uint8) []byte {
+	return Safe{}.AppendUint8(dst, v)
+}
+
+// AppendUint16 appends the Safe encoding of v to dst.
+func AppendUint16(dst []byte, v uint16) []byte {
+	return Safe{}.AppendUint16(dst, v)
+}
+
+// AppendUint32 appends the Safe encoding of v to dst.
+func AppendUint32(dst []byte, v uint32) []byte {
+	return Safe{}.AppendUint32(dst, v)
+}
+
+// AppendUint64 appends the Safe encoding of v to dst.
+func AppendUint64(dst []byte, v uint64) []byte {
+	return Safe{}.AppendUint64(dst, v)
+}
+
+// AppendFloat32 appends the Safe encoding of v to dst.
+func AppendFloat32(dst []byte, v float32) []byte {
+	return Safe{}.AppendFloat32(dst, v)
+}
+
+// AppendFloat64 appends the Safe encoding of v to dst.
+func AppendFloat64(dst []byte, v float64) []byte {
+	return Safe{}.AppendFloat64(dst, v)
+}
+
+// AppendString appends the Safe encoding of v to dst.
+func AppendString(dst []byte, v string) []byte {
+	return Safe{}.AppendString(dst, v)
+}
+
+// AppendByte appends the Safe encoding of v to dst.
+func AppendByte(dst []byte, v byte) []byte {
+	return Safe{}.AppendByte(dst, v)
+}
+
+// AppendBytes appends the Safe encoding of v to dst.
+func AppendBytes(dst []byte, v []byte) []byte {
+	return Safe{}.AppendBytes(dst, v)
+}
+
+// AppendTime appends the Safe encoding of v to dst.
+func AppendTime(dst []byte, v time.Time) []byte {
+	return Safe{}.AppendTime(dst, v)
+}
+
+// AppendDuration appends the Safe encoding of v to dst.
+func AppendDuration(dst []byte, v time.Duration) []byte {
+	return Safe{}.AppendDuration(dst, v)
+}
+
+// AppendGUID appends the encoding of v to dst, the same with every codec.
+func AppendGUID(dst []byte, v GUID) []byte {
+	return Safe{}.AppendGUID(dst, v)
+}
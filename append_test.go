@@ -0,0 +1,69 @@
+package gobin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testAppend checks that appending v to a prefix gives the same bytes as
+// marshaling it.
+func testAppend[T any](v T, m MarshallerFn[T], a func([]byte, T) []byte, r *require.Assertions) {
+	bs := make([]byte, 64)
+	n, err := m(v, bs)
+	r.NoError(err)
+	prefix := []byte{0xaa}
+	r.Equal(append([]byte{0xaa}, bs[:n]...), a(prefix, v))
+}
+
+type appendCodec interface {
+	Marshaler
+	Appender
+}
+
+func TestAppend(t *testing.T) {
+	r := require.New(t)
+	for _, c := range []appendCodec{Safe{}, Unsafe{}, BigEndian{}, Varint{}} {
+		testAppend(true, c.MarshalBool, c.AppendBool, r)
+		testAppend(-1<<40, c.MarshalInt, c.AppendInt, r)
+		testAppend(int8(-3), c.MarshalInt8, c.AppendInt8, r)
+		testAppend(int16(-300), c.MarshalInt16, c.AppendInt16, r)
+		testAppend(int32(math.MinInt32), c.MarshalInt32, c.AppendInt32, r)
+		testAppend(int64(math.MaxInt64), c.MarshalInt64, c.AppendInt64, r)
+		testAppend(uint(1<<40), c.MarshalUint, c.AppendUint, r)
+		testAppend(uint8(200), c.MarshalUint8, c.AppendUint8, r)
+		testAppend(uint16(0x0102), c.MarshalUint16, c.AppendUint16, r)
+		testAppend(uint32(0x01020304), c.MarshalUint32, c.AppendUint32, r)
+		testAppend(uint64(math.MaxUint64), c.MarshalUint64, c.AppendUint64, r)
+		testAppend(float32(1.5), c.MarshalFloat32, c.AppendFloat32, r)
+		testAppend(math.Pi, c.MarshalFloat64, c.AppendFloat64, r)
+		testAppend("hello world", c.MarshalString, c.AppendString, r)
+		testAppend(byte(7), c.MarshalByte, c.AppendByte, r)
+		testAppend([]byte("hello"), c.MarshalBytes, c.AppendBytes, r)
+		testAppend([]byte(nil), c.MarshalBytes, c.AppendBytes, r)
+	}
+	t.Run("package functions use Safe", func(t *testing.T) {
+		testAppend(uint32(0x01020304), Safe{}.MarshalUint32, AppendUint32, r)
+		testAppend("hello", Safe{}.MarshalString, AppendString, r)
+		var dst []byte
+		dst = AppendUint32(dst, 1)
+		dst = AppendString(dst, "a")
+		r.Equal([]byte{1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 'a'}, dst)
+	})
+}
+
+type appenderFunc func([]byte) ([]byte, error)
+
+func (fn appenderFunc) AppendBinary(b []byte) ([]byte, error) {
+	return fn(b)
+}
+
+func TestBufferAppend(t *testing.T) {
+	r := require.New(t)
+	buf := Buffer{Bytes: []byte{1}}
+	r.NoError(buf.Append(appenderFunc(func(b []byte) ([]byte, error) {
+		return AppendUint16(b, 0x0302), nil
+	})))
+	r.Equal([]byte{1, 2, 3}, buf.Bytes)
+}
@@ -0,0 +1,462 @@
+package gobin
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Marshaler = BigEndian{}
+var _ Appender = BigEndian{}
+
+var (
+	marshalBigEndianInt    func(v int, bs []byte) (n int, err error)
+	unmarshalBigEndianInt  func(bs []byte) (v int, n int, err error)
+	marshalBigEndianUint   func(v uint, bs []byte) (n int, err error)
+	unmarshalBigEndianUint func(bs []byte) (v uint, n int, err error)
+)
+
+func init() {
+	marshalBigEndianInt = marshalBigEndianInteger64[int]
+	marshalBigEndianUint = marshalBigEndianInteger64[uint]
+	switch strconv.IntSize {
+	case 32:
+		unmarshalBigEndianInt = unmarshalBigEndianNarrowInt
+		unmarshalBigEndianUint = unmarshalBigEndianNarrowUint
+	case 64:
+		unmarshalBigEndianInt = unmarshalBigEndianInteger64[int]
+		unmarshalBigEndianUint = unmarshalBigEndianInteger64[uint]
+	default:
+		panic("unsupported int size")
+	}
+}
+
+// unmarshalBigEndianNarrowInt decodes a 64-bit int into a 32-bit int.
+func unmarshalBigEndianNarrowInt(bs []byte) (v int, n int, err error) {
+	i, n, err := unmarshalBigEndianInteger64[int64](bs)
+	if err != nil {
+		return
+	}
+	if i < math.MinInt32 || i > math.MaxInt32 {
+		return 0, 0, ErrOverflow
+	}
+	return int(i), n, nil
+}
+
+// unmarshalBigEndianNarrowUint decodes a 64-bit uint into a 32-bit uint.
+func unmarshalBigEndianNarrowUint(bs []byte) (v uint, n int, err error) {
+	i, n, err := unmarshalBigEndianInteger64[uint64](bs)
+	if err != nil {
+		return
+	}
+	if i > math.MaxUint32 {
+		return 0, 0, ErrOverflow
+	}
+	return uint(i), n, nil
+}
+
+func marshalBigEndianInteger16[T Integer16](t T, bs []byte) (n int, err error) {
+	if len(bs) < 2 {
+		return 0, ErrNotEnoughSpace
+	}
+	bs[0] = byte(t >> 8)
+	bs[1] = byte(t)
+	return 2, nil
+}
+
+func marshalBigEndianInteger32[T Integer32](t T, bs []byte) (n int, err error) {
+	if len(bs) < 4 {
+		return 0, ErrNotEnoughSpace
+	}
+	bs[0] = byte(t >> 24)
+	bs[1] = byte(t >> 16)
+	bs[2] = byte(t >> 8)
+	bs[3] = byte(t)
+	return 4, nil
+}
+
+func marshalBigEndianInteger64[T Integer64](t T, bs []byte) (n int, err error) {
+	if len(bs) < 8 {
+		return 0, ErrNotEnoughSpace
+	}
+	bs[0] = byte(t >> 56)
+	bs[1] = byte(t >> 48)
+	bs[2] = byte(t >> 40)
+	bs[3] = byte(t >> 32)
+	bs[4] = byte(t >> 24)
+	bs[5] = byte(t >> 16)
+	bs[6] = byte(t >> 8)
+	bs[7] = byte(t)
+	return 8, nil
+}
+
+func unmarshalBigEndianInteger16[T Integer16](bs []byte) (t T, n int, err error) {
+	if len(bs) < 2 {
+		return 0, 0, ErrNotEnoughSpace
+	}
+	t = T(bs[1])
+	t |= T(bs[0]) << 8
+	return t, 2, nil
+}
+
+func unmarshalBigEndianInteger32[T Integer32](bs []byte) (t T, n int, err error) {
+	if len(bs) < 4 {
+		return 0, 0, ErrNotEnoughSpace
+	}
+	t = T(bs[3])
+	t |= T(bs[2]) << 8
+	t |= T(bs[1]) << 16
+	t |= T(bs[0]) << 24
+	return t, 4, nil
+}
+
+func unmarshalBigEndianInteger64[T Integer64](bs []byte) (t T, n int, err error) {
+	if len(bs) < 8 {
+		return 0, 0, ErrNotEnoughSpace
+	}
+	t = T(bs[7])
+	t |= T(bs[6]) << 8
+	t |= T(bs[5]) << 16
+	t |= T(bs[4]) << 24
+	t |= T(bs[3]) << 32
+	t |= T(bs[2]) << 40
+	t |= T(bs[1]) << 48
+	t |= T(bs[0]) << 56
+	return t, 8, nil
+}
+
+func appendBigEndianInteger16[T Integer16](dst []byte, t T) []byte {
+	return append(dst, byte(t>>8), byte(t))
+}
+
+func appendBigEndianInteger32[T Integer32](dst []byte, t T) []byte {
+	return append(dst, byte(t>>24), byte(t>>16), byte(t>>8), byte(t))
+}
+
+func appendBigEndianInteger64[T Integer64](dst []byte, t T) []byte {
+	return append(dst, byte(t>>56), byte(t>>48), byte(t>>40), byte(t>>32), byte(t>>24), byte(t>>16), byte(t>>8), byte(t))
+}
+
+// BigEndian encodes values like Safe, but in network byte order, including
+// the length prefixes of strings and bytes.
+type BigEndian struct{}
+
+func (BigEndian) MarshalBool(v bool, bs []byte) (n int, err error) {
+	return marshalBool(v, bs)
+}
+
+func (BigEndian) UnmarshalBool(bs []byte) (v bool, n int, err error) {
+	return unmarshalBool(bs)
+}
+
+func (BigEndian) MarshalInt(v int, bs []byte) (n int, err error) {
+	return marshalBigEndianInt(v, bs)
+}
+
+func (BigEndian) UnmarshalInt(bs []byte) (v int, n int, err error) {
+	return unmarshalBigEndianInt(bs)
+}
+
+func (BigEndian) MarshalInt8(v int8, bs []byte) (n int, err error) {
+	return marshalSafeInteger8(v, bs)
+}
+
+func (BigEndian) UnmarshalInt8(bs []byte) (v int8, n int, err error) {
+	return unmarshalSafeInteger8[int8](bs)
+}
+
+func (BigEndian) MarshalInt16(v int16, bs []byte) (n int, err error) {
+	return marshalBigEndianInteger16(v, bs)
+}
+
+func (BigEndian) UnmarshalInt16(bs []byte) (v int16, n int, err error) {
+	return unmarshalBigEndianInteger16[int16](bs)
+}
+
+func (BigEndian) MarshalInt32(v int32, bs []byte) (n int, err error) {
+	return marshalBigEndianInteger32(v, bs)
+}
+
+func (BigEndian) UnmarshalInt32(bs []byte) (v int32, n int, err error) {
+	return unmarshalBigEndianInteger32[int32](bs)
+}
+
+func (BigEndian) MarshalInt64(v int64, bs []byte) (n int, err error) {
+	return marshalBigEndianInteger64(v, bs)
+}
+
+func (BigEndian) UnmarshalInt64(bs []byte) (v int64, n int, err error) {
+	return unmarshalBigEndianInteger64[int64](bs)
+}
+
+func (BigEndian) MarshalUint(v uint, bs []byte) (n int, err error) {
+	return marshalBigEndianUint(v, bs)
+}
+
+func (BigEndian) UnmarshalUint(bs []byte) (v uint, n int, err error) {
+	return unmarshalBigEndianUint(bs)
+}
+
+func (BigEndian) MarshalUint8(v uint8, bs []byte) (n int, err error) {
+	return marshalSafeInteger8(v, bs)
+}
+
+func (BigEndian) UnmarshalUint8(bs []byte) (v uint8, n int, err error) {
+	return unmarshalSafeInteger8[uint8](bs)
+}
+
+func (BigEndian) MarshalUint16(v uint16, bs []byte) (n int, err error) {
+	return marshalBigEndianInteger16(v, bs)
+}
+
+func (BigEndian) UnmarshalUint16(bs []byte) (v uint16, n int, err error) {
+	return unmarshalBigEndianInteger16[uint16](bs)
+}
+
+func (BigEndian) MarshalUint32(v uint32, bs []byte) (n int, err error) {
+	return marshalBigEndianInteger32(v, bs)
+}
+
+func (BigEndian) UnmarshalUint32(bs []byte) (v uint32, n int, err error) {
+	return unmarshalBigEndianInteger32[uint32](bs)
+}
+
+func (BigEndian) MarshalUint64(v uint64, bs []byte) (n int, err error) {
+	return marshalBigEndianInteger64(v, bs)
+}
+
+func (BigEndian) UnmarshalUint64(bs []byte) (v uint64, n int, err error) {
+	return unmarshalBigEndianInteger64[uint64](bs)
+}
+
+func (BigEndian) MarshalString(v string, bs []byte) (n int, err error) {
+	n, err = marshalBigEndianInt(len(v), bs)
+	if err != nil {
+		return
+	}
+	if len(bs[n:]) < len(v) {
+		return 0, ErrNotEnoughSpace
+	}
+	return n + copy(bs[n:], v), nil
+}
+
+// UnmarshalString decodes a copy of the string at the start of bs.
+func (c BigEndian) UnmarshalString(bs []byte) (v string, n int, err error) {
+	v, n, err = c.BorrowString(bs)
+	return strings.Clone(v), n, err
+}
+
+// BorrowString decodes the string at the start of bs, aliasing bs.
+func (BigEndian) BorrowString(bs []byte) (v string, n int, err error) {
+	l, n, err := unmarshalBigEndianInt(bs)
+	if err != nil {
+		return
+	}
+	if l < 0 {
+		err = ErrNegativeLength
+		return
+	}
+	if len(bs[n:]) < int(l) {
+		err = ErrNotEnoughSpace
+		return
+	}
+	return aliasString(bs[n : n+l]), n + l, nil
+}
+
+func (BigEndian) MarshalByte(v byte, bs []byte) (n int, err error) {
+	return marshalSafeInteger8(v, bs)
+}
+
+func (BigEndian) UnmarshalByte(bs []byte) (v byte, n int, err error) {
+	return unmarshalSafeInteger8[byte](bs)
+}
+
+// MarshalBytes encodes v as []byte. [isnil:bool][len:int][v:[]byte]
+func (BigEndian) MarshalBytes(v []byte, bs []byte) (n int, err error) {
+	if v == nil {
+		return marshalBool(true, bs)
+	}
+	if _, err = marshalBool(false, bs); err != nil {
+		return
+	}
+	n, err = marshalBigEndianInt(len(v), bs[1:])
+	if err != nil {
+		return
+	}
+	n += 1
+	if len(bs[n:]) < len(v) {
+		return 0, ErrNotEnoughSpace
+	}
+	return n + copy(bs[n:], v), nil
+}
+
+func (BigEndian) UnmarshalBytes(bs []byte) (v []byte, n int, err error) {
+	isNil, n, err := unmarshalBool(bs)
+	if err != nil {
+		return
+	}
+	if isNil {
+		return nil, n, nil
+	}
+	l, n, err := unmarshalBigEndianInt(bs[1:])
+	if err != nil {
+		return
+	}
+	n += 1
+	if l < 0 {
+		err = ErrNegativeLength
+		return
+	} else if l == 0 {
+		return []byte{}, n, nil
+	}
+	if len(bs[n:]) < int(l) {
+		err = ErrNotEnoughSpace
+		return
+	}
+	return bs[n : n+l], n + l, nil
+}
+
+func (BigEndian) MarshalFloat32(v float32, bs []byte) (n int, err error) {
+	return marshalBigEndianInteger32(math.Float32bits(v), bs)
+}
+
+func (BigEndian) UnmarshalFloat32(bs []byte) (v float32, n int, err error) {
+	uv, n, err := unmarshalBigEndianInteger32[uint32](bs)
+	if err != nil {
+		return
+	}
+	return math.Float32frombits(uv), n, nil
+}
+
+func (BigEndian) MarshalFloat64(v float64, bs []byte) (n int, err error) {
+	return marshalBigEndianInteger64(math.Float64bits(v), bs)
+}
+
+func (BigEndian) UnmarshalFloat64(bs []byte) (v float64, n int, err error) {
+	uv, n, err := unmarshalBigEndianInteger64[uint64](bs)
+	if err != nil {
+		return
+	}
+	return math.Float64frombits(uv), n, nil
+}
+
+func (BigEndian) AppendBool(dst []byte, v bool) []byte {
+	return appendBool(dst, v)
+}
+
+func (BigEndian) AppendInt(dst []byte, v int) []byte {
+	return appendBigEndianInteger64(dst, int64(v))
+}
+
+func (BigEndian) AppendInt8(dst []byte, v int8) []byte {
+	return append(dst, byte(v))
+}
+
+func (BigEndian) AppendInt16(dst []byte, v int16) []byte {
+	return appendBigEndianInteger16(dst, v)
+}
+
+func (BigEndian) AppendInt32(dst []byte, v int32) []byte {
+	return appendBigEndianInteger32(dst, v)
+}
+
+func (BigEndian) AppendInt64(dst []byte, v int64) []byte {
+	return appendBigEndianInteger64(dst, v)
+}
+
+func (BigEndian) AppendUint(dst []byte, v uint) []byte {
+	return appendBigEndianInteger64(dst, uint64(v))
+}
+
+func (BigEndian) AppendUint8(dst []byte, v uint8) []byte {
+	return append(dst, byte(v))
+}
+
+func (BigEndian) AppendUint16(dst []byte, v uint16) []byte {
+	return appendBigEndianInteger16(dst, v)
+}
+
+func (BigEndian) AppendUint32(dst []byte, v uint32) []byte {
+	return appendBigEndianInteger32(dst, v)
+}
+
+func (BigEndian) AppendUint64(dst []byte, v uint64) []byte {
+	return appendBigEndianInteger64(dst, v)
+}
+
+func (BigEndian) AppendFloat32(dst []byte, v float32) []byte {
+	return appendBigEndianInteger32(dst, math.Float32bits(v))
+}
+
+func (BigEndian) AppendFloat64(dst []byte, v float64) []byte {
+	return appendBigEndianInteger64(dst, math.Float64bits(v))
+}
+
+func (BigEndian) AppendString(dst []byte, v string) []byte {
+	dst = appendBigEndianInteger64(dst, int64(len(v)))
+	return append(dst, v...)
+}
+
+func (BigEndian) AppendByte(dst []byte, v byte) []byte {
+	return append(dst, byte(v))
+}
+
+func (c BigEndian) AppendBytes(dst []byte, v []byte) []byte {
+	return append(c.appendBytesPrefix(dst, v), v...)
+}
+
+// appendBytesPrefix appends the isnil flag and length preceding v.
+func (BigEndian) appendBytesPrefix(dst []byte, v []byte) []byte {
+	if v == nil {
+		return appendBool(dst, true)
+	}
+	dst = appendBool(dst, false)
+	return appendBigEndianInteger64(dst, int64(len(v)))
+}
+
+func (c BigEndian) MarshalTime(v time.Time, bs []byte) (n int, err error) {
+	return marshalTime(c, v, bs)
+}
+
+func (c BigEndian) UnmarshalTime(bs []byte) (v time.Time, n int, err error) {
+	return unmarshalTime(c, bs)
+}
+
+func (c BigEndian) AppendTime(dst []byte, v time.Time) []byte {
+	return appendTime(c, dst, v)
+}
+
+func (c BigEndian) MarshalDuration(v time.Duration, bs []byte) (n int, err error) {
+	return c.MarshalInt64(int64(v), bs)
+}
+
+func (c BigEndian) UnmarshalDuration(bs []byte) (v time.Duration, n int, err error) {
+	i, n, err := c.UnmarshalInt64(bs)
+	return time.Duration(i), n, err
+}
+
+func (c BigEndian) AppendDuration(dst []byte, v time.Duration) []byte {
+	return c.AppendInt64(dst, int64(v))
+}
+
+func (BigEndian) MarshalGUID(v GUID, bs []byte) (n int, err error) {
+	return marshalGUID(v, bs)
+}
+
+func (BigEndian) UnmarshalGUID(bs []byte) (v GUID, n int, err error) {
+	return unmarshalGUID(bs)
+}
+
+func (BigEndian) AppendGUID(dst []byte, v GUID) []byte {
+	return appendGUID(dst, v)
+}
+
+func (BigEndian) MarshalBinary() ([]byte, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (BigEndian) UnmarshalBinary([]byte) error {
+	return fmt.Errorf("unimplemented")
+}
@@ -0,0 +1,95 @@
+package gobin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBigEndian(t *testing.T) {
+	r := require.New(t)
+	v := BigEndian{}
+	t.Run("network byte order", func(t *testing.T) {
+		bs := make([]byte, 8)
+		n, err := v.MarshalUint16(0x0102, bs)
+		r.NoError(err)
+		r.Equal([]byte{0x01, 0x02}, bs[:n])
+		n, err = v.MarshalUint32(0x01020304, bs)
+		r.NoError(err)
+		r.Equal([]byte{0x01, 0x02, 0x03, 0x04}, bs[:n])
+		n, err = v.MarshalInt64(-2, bs)
+		r.NoError(err)
+		r.Equal([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}, bs[:n])
+		n, err = v.MarshalFloat32(1.0, bs)
+		r.NoError(err)
+		r.Equal([]byte{0x3f, 0x80, 0x00, 0x00}, bs[:n])
+	})
+	t.Run("int", func(t *testing.T) {
+		testA[int16](1, v.MarshalInt16, v.UnmarshalInt16, 2, r)
+		testA[int32](1, v.MarshalInt32, v.UnmarshalInt32, 4, r)
+		testA[int64](1, v.MarshalInt64, v.UnmarshalInt64, 8, r)
+		bs := make([]byte, 8)
+		n, err := v.MarshalInt32(math.MinInt32, bs)
+		r.NoError(err)
+		r.Equal(4, n)
+		i, n, err := v.UnmarshalInt32(bs)
+		r.NoError(err)
+		r.Equal(4, n)
+		r.Equal(int32(math.MinInt32), i)
+	})
+	t.Run("uint", func(t *testing.T) {
+		testA[uint16](1, v.MarshalUint16, v.UnmarshalUint16, 2, r)
+		testA[uint32](1, v.MarshalUint32, v.UnmarshalUint32, 4, r)
+		testA[uint64](1, v.MarshalUint64, v.UnmarshalUint64, 8, r)
+		bs := make([]byte, 8)
+		n, err := v.MarshalUint64(math.MaxUint64-1, bs)
+		r.NoError(err)
+		r.Equal(8, n)
+		i, n, err := v.UnmarshalUint64(bs)
+		r.NoError(err)
+		r.Equal(8, n)
+		r.Equal(uint64(math.MaxUint64-1), i)
+	})
+	t.Run("float64", func(t *testing.T) {
+		bs := make([]byte, 8)
+		n, err := v.MarshalFloat64(math.Pi, bs)
+		r.NoError(err)
+		r.Equal(8, n)
+		f, n, err := v.UnmarshalFloat64(bs)
+		r.NoError(err)
+		r.Equal(8, n)
+		r.Equal(math.Pi, f)
+	})
+	t.Run("string", func(t *testing.T) {
+		s := "hello world"
+		bs := make([]byte, 100)
+		n, err := v.MarshalString(s, bs)
+		r.NoError(err)
+		r.Equal(19, n)
+		r.Equal(byte(11), bs[7])
+		s2, n, err := v.UnmarshalString(bs)
+		r.NoError(err)
+		r.Equal(19, n)
+		r.Equal(s, s2)
+	})
+	t.Run("bytes", func(t *testing.T) {
+		bs := []byte("hello world")
+		bs2 := make([]byte, 100)
+		n, err := v.MarshalBytes(bs, bs2)
+		r.NoError(err)
+		r.Equal(20, n)
+		bs3, n, err := v.UnmarshalBytes(bs2)
+		r.NoError(err)
+		r.Equal(20, n)
+		r.Equal(bs, bs3)
+
+		n, err = v.MarshalBytes(nil, bs2)
+		r.NoError(err)
+		r.Equal(1, n)
+		bs3, n, err = v.UnmarshalBytes(bs2)
+		r.NoError(err)
+		r.Equal(1, n)
+		r.Nil(bs3)
+	})
+}
@@ -0,0 +1,137 @@
+package gobin
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrBitOverflow is returned when a value does not fit in the width of its
+// bit field.
+var ErrBitOverflow = errors.New("value overflows bit width")
+
+// Bit fields are the opt-in encoding of integer fields tagged gobin:"bits=N",
+// or declared with the [bits = N] schema option, N being from 1 to 64. A run
+// of consecutive bit fields is written with no byte alignment, whatever the
+// codec, least significant bit first as with packed bools: bit i of the run is
+// bit i%8 of byte i/8. The run takes PackedSize of its total width in bytes,
+// the unused high bits of the last byte being zero. Signed fields are written
+// in two's complement.
+
+// BitWriter appends bit fields to a byte slice.
+type BitWriter struct {
+	buf []byte
+	// free is the number of unused bits of the last byte of buf.
+	free int
+}
+
+// NewBitWriter returns a BitWriter appending to dst.
+func NewBitWriter(dst []byte) *BitWriter {
+	return &BitWriter{buf: dst}
+}
+
+// WriteBits writes the width low bits of v, returning ErrBitOverflow if v
+// has higher bits set.
+func (w *BitWriter) WriteBits(v uint64, width int) error {
+	if width < 64 && v>>width != 0 {
+		return ErrBitOverflow
+	}
+	for width > 0 {
+		if w.free == 0 {
+			w.buf = append(w.buf, 0)
+			w.free = 8
+		}
+		w.buf[len(w.buf)-1] |= byte(v << (8 - w.free))
+		n := min(w.free, width)
+		v >>= n
+		width -= n
+		w.free -= n
+	}
+	return nil
+}
+
+// WriteSignedBits writes v in width bits, returning ErrBitOverflow if it is
+// out of their range.
+func (w *BitWriter) WriteSignedBits(v int64, width int) error {
+	if width < 64 && (v < -1<<(width-1) || v >= 1<<(width-1)) {
+		return ErrBitOverflow
+	}
+	if width < 64 {
+		v &= 1<<width - 1
+	}
+	return w.WriteBits(uint64(v), width)
+}
+
+// Bytes returns the slice passed to NewBitWriter with the bits written
+// appended.
+func (w *BitWriter) Bytes() []byte {
+	return w.buf
+}
+
+// BitReader reads bit fields. As with Reader, the first error stops the
+// reads, which then return zero, and is returned by Finish.
+type BitReader struct {
+	data []byte
+	// off is the number of bits read.
+	off int
+	err error
+}
+
+// NewBitReader returns a BitReader reading data from its first bit.
+func NewBitReader(data []byte) *BitReader {
+	return &BitReader{data: data}
+}
+
+// ReadBits reads an unsigned value of width bits.
+func (r *BitReader) ReadBits(width int) (v uint64) {
+	if r.err != nil {
+		return 0
+	}
+	if r.off+width > 8*len(r.data) {
+		r.err = ErrNotEnoughSpace
+		return 0
+	}
+	for i := 0; i < width; {
+		n := min(8-r.off%8, width-i)
+		b := r.data[r.off/8] >> (r.off % 8)
+		v |= uint64(b&byte(1<<n-1)) << i
+		i += n
+		r.off += n
+	}
+	return v
+}
+
+// ReadSignedBits reads a signed value of width bits.
+func (r *BitReader) ReadSignedBits(width int) int64 {
+	shift := 64 - width
+	return int64(r.ReadBits(width)<<shift) >> shift
+}
+
+// Finish returns the number of bytes read, counting the last one read from,
+// and the first error: ErrInvalidPadding if the unused high bits of the last
+// byte are set.
+func (r *BitReader) Finish() (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n := (r.off + 7) / 8
+	if r.off%8 != 0 && r.data[n-1]>>(r.off%8) != 0 {
+		return 0, ErrInvalidPadding
+	}
+	return n, nil
+}
+
+// bitsTag returns the width of a bit field tagged gobin:"bits=N", 0 if tag,
+// the value of its gobin key, is not a bits tag, or -1 if N is not from 1 to
+// 64.
+func bitsTag(tag string) int {
+	s, ok := strings.CutPrefix(tag, "bits=")
+	if !ok {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > 64 {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,118 @@
+package gobin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitWriterReader(t *testing.T) {
+	r := require.New(t)
+	w := NewBitWriter([]byte{0xaa})
+	r.NoError(w.WriteBits(5, 3))
+	r.NoError(w.WriteBits(0xabc, 12))
+	r.NoError(w.WriteSignedBits(-3, 5))
+	r.NoError(w.WriteBits(math.MaxUint64, 64))
+	r.NoError(w.WriteSignedBits(math.MinInt64, 64))
+	r.NoError(w.WriteBits(1, 1))
+	data := w.Bytes()
+	// 3+12+5+64+64+1 bits take 19 bytes after the one passed in
+	r.Len(data, 20)
+	r.Equal([]byte{0xaa, 0xe5, 0xd5, 0xfe}, data[:4])
+
+	br := NewBitReader(data[1:])
+	r.Equal(uint64(5), br.ReadBits(3))
+	r.Equal(uint64(0xabc), br.ReadBits(12))
+	r.Equal(int64(-3), br.ReadSignedBits(5))
+	r.Equal(uint64(math.MaxUint64), br.ReadBits(64))
+	r.Equal(int64(math.MinInt64), br.ReadSignedBits(64))
+	r.Equal(uint64(1), br.ReadBits(1))
+	n, err := br.Finish()
+	r.NoError(err)
+	r.Equal(19, n)
+
+	br = NewBitReader(data[1:])
+	br.ReadBits(64)
+	br.ReadBits(64)
+	r.Equal(uint64(0), br.ReadBits(64))
+	_, err = br.Finish()
+	r.ErrorIs(err, ErrNotEnoughSpace)
+}
+
+func TestBitOverflow(t *testing.T) {
+	r := require.New(t)
+	w := NewBitWriter(nil)
+	r.ErrorIs(w.WriteBits(8, 3), ErrBitOverflow)
+	r.NoError(w.WriteBits(7, 3))
+	r.ErrorIs(w.WriteSignedBits(4, 3), ErrBitOverflow)
+	r.ErrorIs(w.WriteSignedBits(-5, 3), ErrBitOverflow)
+	r.NoError(w.WriteSignedBits(-4, 3))
+	r.NoError(w.WriteSignedBits(3, 3))
+	r.Equal([]byte{0b011_100_111}, w.Bytes()[:1])
+}
+
+func TestBitPadding(t *testing.T) {
+	r := require.New(t)
+	br := NewBitReader([]byte{0b1000_0101})
+	r.Equal(uint64(5), br.ReadBits(3))
+	_, err := br.Finish()
+	r.ErrorIs(err, ErrInvalidPadding)
+
+	rd := NewReader(Safe{}, []byte{0b0000_0101, 9})
+	rd.Field("Channel")
+	rd.ReadBitFields(func(br *BitReader) {
+		r.Equal(uint64(5), br.ReadBits(3))
+	})
+	r.Equal(uint8(9), rd.ReadUint8())
+	r.NoError(rd.Err())
+
+	rd = NewReader(Safe{}, []byte{0b0100_0101})
+	rd.Field("Channel")
+	rd.ReadBitFields(func(br *BitReader) { br.ReadBits(3) })
+	var de *DecodeError
+	r.ErrorAs(rd.Err(), &de)
+	r.Equal("Channel", de.Field)
+	r.Equal("bits", de.Type)
+	r.ErrorIs(de, ErrInvalidPadding)
+}
+
+type radio struct {
+	Safe
+	Channel uint8  `gobin:"bits=3"`
+	Reading uint16 `gobin:"bits=12"`
+	Offset  int8   `gobin:"bits=5"`
+	Seq     uint32
+	Inner   struct {
+		A uint8 `gobin:"bits=2"`
+	}
+}
+
+func TestReflectBits(t *testing.T) {
+	r := require.New(t)
+	v := radio{Channel: 5, Reading: 0xabc, Offset: -3, Seq: 1}
+	v.Inner.A = 200
+	data, err := Marshal(&v)
+	r.NoError(err)
+	// nested structs have no bit fields
+	r.Equal([]byte{0xe5, 0xd5, 0x0e, 1, 0, 0, 0, 200}, data)
+	var got radio
+	r.NoError(Unmarshal(data, &got))
+	r.Equal(v, got)
+
+	v.Reading = 1 << 12
+	_, err = Marshal(&v)
+	r.ErrorIs(err, ErrBitOverflow)
+	r.ErrorContains(err, "Reading")
+
+	type wide struct {
+		A uint8 `gobin:"bits=9"`
+	}
+	_, err = Marshal(wide{})
+	var ute *UnsupportedTypeError
+	r.ErrorAs(err, &ute)
+	type text struct {
+		A string `gobin:"bits=3"`
+	}
+	r.ErrorAs(Unmarshal(nil, &text{}), &ute)
+}
@@ -3,6 +3,7 @@ package gobin
 import (
 	"io"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -17,7 +18,8 @@ type Buffer struct {
 	Bytes []byte
 }
 
-var _ io.Writer = &Buffer{} // commit to compatibility with io.Writer
+var _ io.Writer = &Buffer{}     // commit to compatibility with io.Writer
+var _ io.ReaderFrom = &Buffer{} // and io.ReaderFrom
 
 // Write a chunk of bytes to the buffer
 func (b *Buffer) Write(v []byte) (int, error) {
@@ -36,6 +38,12 @@ func (b *Buffer) WriteByte(v byte) error {
 	return nil
 }
 
+// Append appends the encoding of v to the buffer
+func (b *Buffer) Append(v BinaryAppender) (err error) {
+	b.Bytes, err = v.AppendBinary(b.Bytes)
+	return
+}
+
 // Reset allows this to be reused by emptying
 func (b *Buffer) Reset() {
 	b.Bytes = b.Bytes[:0]
@@ -45,42 +53,175 @@ func (b *Buffer) String() string {
 	return *(*string)(unsafe.Pointer(&b.Bytes))
 }
 
+// Len returns the number of bytes in the buffer
+func (b *Buffer) Len() int {
+	return len(b.Bytes)
+}
+
+// Cap returns the capacity of the buffer
+func (b *Buffer) Cap() int {
+	return cap(b.Bytes)
+}
+
+// Grow grows the buffer's capacity, if necessary, to hold n more bytes
+func (b *Buffer) Grow(n int) {
+	if cap(b.Bytes)-len(b.Bytes) < n {
+		nb := make([]byte, len(b.Bytes), 2*cap(b.Bytes)+n)
+		copy(nb, b.Bytes)
+		b.Bytes = nb
+	}
+}
+
+// minRead is the least free space ReadFrom reads into, as in bytes.Buffer
+const minRead = 512
+
+// ReadFrom reads from r until EOF, appending to the buffer
+func (b *Buffer) ReadFrom(r io.Reader) (int64, error) {
+	var total int64
+	for {
+		b.Grow(minRead)
+		n, err := r.Read(b.Bytes[len(b.Bytes):cap(b.Bytes)])
+		if n < 0 {
+			panic("gobin: reader returned negative count from Read")
+		}
+		b.Bytes = b.Bytes[:len(b.Bytes)+n]
+		total += int64(n)
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
 // WriteTo writes the contents of our buffer to an io.Writer
 func (b *Buffer) WriteTo(w io.Writer) (int64, error) {
 	n, err := w.Write(b.Bytes)
 	return int64(n), err
 }
 
-var bufpool = sync.Pool{
-	New: func() interface{} { return &Buffer{} },
+// Pooled buffers are kept in size classes, so that a large message does not
+// inflate a buffer that later serves small ones. Class i holds buffers of at
+// least minBufferClass<<(2*i) bytes, from 256 B up to 1 MiB.
+const (
+	minBufferClass   = 256
+	numBufferClasses = 7
+)
+
+// MaxPooledBufferSize is the capacity above which ReturnToPool drops a
+// buffer instead of keeping it.
+var MaxPooledBufferSize = minBufferClass << (2 * (numBufferClasses - 1))
+
+var bufpools [numBufferClasses]sync.Pool
+
+// bufferClassSize returns the capacity of the buffers of class i.
+func bufferClassSize(i int) int {
+	return minBufferClass << (2 * i)
 }
 
 // NewBufferFromPool returns a pointer to a zerod Buffer. This may be retrieved from a
 // pool. When you're done with it, call 'ReturnToPool'.
 func NewBufferFromPool() *Buffer {
-	b := bufpool.Get().(*Buffer)
-	b.Reset()
-	return b
+	return NewBufferFromPoolWithCap(0)
 }
 
 // NewBufferFromPoolWithCap returns a pointer to a zero'd Buffer with its underlying
 // capacity set. This may be retrieved from a pool. When you're done with it, call 'ReturnToPool'.
 func NewBufferFromPoolWithCap(size int) *Buffer {
-	b := bufpool.Get().(*Buffer)
-
-	if c := cap(b.Bytes); c < size {
-		b.Bytes = make([]byte, 0, size)
-	} else if c > 0 {
-		b.Reset()
+	bufferStats.get()
+	i := 0
+	for i < numBufferClasses && bufferClassSize(i) < size {
+		i++
 	}
-
+	if i == numBufferClasses {
+		return &Buffer{Bytes: make([]byte, 0, size)}
+	}
+	b, ok := bufpools[i].Get().(*Buffer)
+	if !ok {
+		return &Buffer{Bytes: make([]byte, 0, bufferClassSize(i))}
+	}
+	bufferStats.retain(-cap(b.Bytes))
+	b.Reset()
 	return b
 }
 
 // ReturnToPool puts this instance back in the underlying pool. Reading from or using this instance
-// in any way after calling this is invalid.
+// in any way after calling this is invalid. Buffers larger than MaxPooledBufferSize
+// are dropped.
 func (b *Buffer) ReturnToPool() {
-	bufpool.Put(b)
+	c := cap(b.Bytes)
+	if c < minBufferClass || c > MaxPooledBufferSize {
+		bufferStats.drop()
+		return
+	}
+	i := 0
+	for i+1 < numBufferClasses && bufferClassSize(i+1) <= c {
+		i++
+	}
+	bufferStats.put(c)
+	bufpools[i].Put(b)
+}
+
+// BufferPoolStats counts the use of the buffer pool, once enabled with
+// EnableBufferPoolStats.
+type BufferPoolStats struct {
+	Gets  uint64 // buffers taken from the pool
+	Puts  uint64 // buffers returned to the pool
+	Drops uint64 // buffers returned but too small or large to keep
+	// Retained is the capacity of the buffers returned and not taken again.
+	// It is an upper bound, as the pool may free buffers at any time.
+	Retained int64
+}
+
+type bufferPoolCounters struct {
+	enabled           atomic.Bool
+	gets, puts, drops atomic.Uint64
+	retained          atomic.Int64
+}
+
+var bufferStats bufferPoolCounters
+
+func (c *bufferPoolCounters) get() {
+	if c.enabled.Load() {
+		c.gets.Add(1)
+	}
+}
+
+func (c *bufferPoolCounters) put(size int) {
+	if c.enabled.Load() {
+		c.puts.Add(1)
+		c.retained.Add(int64(size))
+	}
+}
+
+func (c *bufferPoolCounters) drop() {
+	if c.enabled.Load() {
+		c.drops.Add(1)
+	}
+}
+
+func (c *bufferPoolCounters) retain(size int) {
+	if c.enabled.Load() {
+		c.retained.Add(int64(size))
+	}
+}
+
+// EnableBufferPoolStats turns the counters of ReadBufferPoolStats on or off.
+// They are off by default, sparing the pool the atomic operations.
+func EnableBufferPoolStats(on bool) {
+	bufferStats.enabled.Store(on)
+}
+
+// ReadBufferPoolStats returns the counters of the buffer pool, e.g. to export
+// them as metrics.
+func ReadBufferPoolStats() BufferPoolStats {
+	return BufferPoolStats{
+		Gets:     bufferStats.gets.Load(),
+		Puts:     bufferStats.puts.Load(),
+		Drops:    bufferStats.drops.Load(),
+		Retained: bufferStats.retained.Load(),
+	}
 }
 
 // const _size = 1024 // by default, create 1 KiB buffers
@@ -0,0 +1,71 @@
+package gobin
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuffer(t *testing.T) {
+	r := require.New(t)
+	t.Run("grow", func(t *testing.T) {
+		var b Buffer
+		b.WriteString("ab")
+		b.Grow(100)
+		r.Equal(2, b.Len())
+		r.GreaterOrEqual(b.Cap(), 102)
+		c := b.Cap()
+		b.Grow(10)
+		r.Equal(c, b.Cap())
+		r.Equal("ab", b.String())
+	})
+	t.Run("read from", func(t *testing.T) {
+		var b Buffer
+		b.WriteString(">")
+		src := strings.Repeat("0123456789", 300)
+		n, err := b.ReadFrom(iotest.OneByteReader(strings.NewReader(src)))
+		r.NoError(err)
+		r.Equal(int64(len(src)), n)
+		r.Equal(">"+src, b.String())
+
+		_, err = b.ReadFrom(iotest.ErrReader(iotest.ErrTimeout))
+		r.ErrorIs(err, iotest.ErrTimeout)
+		var out bytes.Buffer
+		_, err = b.WriteTo(&out)
+		r.NoError(err)
+		r.Equal(">"+src, out.String())
+	})
+	t.Run("should pool buffers by size class", func(t *testing.T) {
+		EnableBufferPoolStats(true)
+		defer EnableBufferPoolStats(false)
+		before := ReadBufferPoolStats()
+
+		small := NewBufferFromPool()
+		r.GreaterOrEqual(small.Cap(), minBufferClass)
+		large := NewBufferFromPoolWithCap(5000)
+		r.GreaterOrEqual(large.Cap(), 5000)
+		r.Less(large.Cap(), 4*5000)
+		huge := NewBufferFromPoolWithCap(MaxPooledBufferSize + 1)
+		r.GreaterOrEqual(huge.Cap(), MaxPooledBufferSize+1)
+		small.Grow(10 << 20) // inflated by a large message
+		mid := ReadBufferPoolStats()
+
+		small.ReturnToPool()
+		large.ReturnToPool()
+		huge.ReturnToPool()
+		(&Buffer{}).ReturnToPool()
+
+		after := ReadBufferPoolStats()
+		r.Equal(uint64(3), after.Gets-before.Gets)
+		r.Equal(uint64(1), after.Puts-before.Puts)
+		r.Equal(uint64(3), after.Drops-before.Drops)
+		r.Equal(int64(large.Cap()), after.Retained-mid.Retained)
+
+		b := NewBufferFromPoolWithCap(100)
+		r.Less(b.Cap(), 10<<20)
+		r.Zero(b.Len())
+	})
+}
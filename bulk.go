@@ -0,0 +1,434 @@
+package gobin
+
+import (
+	"math"
+	"slices"
+	"unsafe"
+)
+
+// The methods below encode the elements of numeric slices and arrays, without
+// a length prefix: the bytes are those of encoding every element in turn,
+// e.g. MarshalFloat64s(v, bs) gives the bytes of MarshalFloat64 for v[0],
+// v[1] and so on. The Unmarshal methods decode len(v) elements into v.
+//
+// Unsafe copies the memory of v in one copy, Safe loops over the elements. On
+// little-endian hosts both give the same bytes. cmd/bingen uses them for
+// slices and arrays of these types.
+
+// bulkNumber is a constraint that permits the types with bulk methods.
+type bulkNumber interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~float32 | ~float64
+}
+
+// sliceBytes returns the memory of v.
+func sliceBytes[T bulkNumber](v []T) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(v))), len(v)*int(unsafe.Sizeof(T(0))))
+}
+
+func marshalUnsafeSlice[T bulkNumber](v []T, bs []byte) (int, error) {
+	b := sliceBytes(v)
+	if len(bs) < len(b) {
+		return 0, ErrNotEnoughSpace
+	}
+	return copy(bs, b), nil
+}
+
+func unmarshalUnsafeSlice[T bulkNumber](v []T, bs []byte) (int, error) {
+	b := sliceBytes(v)
+	if len(bs) < len(b) {
+		return 0, ErrNotEnoughSpace
+	}
+	return copy(b, bs), nil
+}
+
+func appendUnsafeSlice[T bulkNumber](dst []byte, v []T) []byte {
+	return append(dst, sliceBytes(v)...)
+}
+
+// checkSafeSlice reports whether bs holds n elements of the given size.
+func checkSafeSlice(n, size int, bs []byte) error {
+	if len(bs) < n*size {
+		return ErrNotEnoughSpace
+	}
+	return nil
+}
+
+func marshalSafeSlice16[T Integer16](v []T, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 2, bs); err != nil {
+		return 0, err
+	}
+	for i, e := range v {
+		marshalSafeInteger16(e, bs[i*2:])
+	}
+	return len(v) * 2, nil
+}
+
+func marshalSafeSlice32[T Integer32](v []T, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 4, bs); err != nil {
+		return 0, err
+	}
+	for i, e := range v {
+		marshalSafeInteger32(e, bs[i*4:])
+	}
+	return len(v) * 4, nil
+}
+
+func marshalSafeSlice64[T Integer64](v []T, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 8, bs); err != nil {
+		return 0, err
+	}
+	for i, e := range v {
+		marshalSafeInteger64(e, bs[i*8:])
+	}
+	return len(v) * 8, nil
+}
+
+func unmarshalSafeSlice16[T Integer16](v []T, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 2, bs); err != nil {
+		return 0, err
+	}
+	for i := range v {
+		v[i], _, _ = unmarshalSafeInteger16[T](bs[i*2:])
+	}
+	return len(v) * 2, nil
+}
+
+func unmarshalSafeSlice32[T Integer32](v []T, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 4, bs); err != nil {
+		return 0, err
+	}
+	for i := range v {
+		v[i], _, _ = unmarshalSafeInteger32[T](bs[i*4:])
+	}
+	return len(v) * 4, nil
+}
+
+func unmarshalSafeSlice64[T Integer64](v []T, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 8, bs); err != nil {
+		return 0, err
+	}
+	for i := range v {
+		v[i], _, _ = unmarshalSafeInteger64[T](bs[i*8:])
+	}
+	return len(v) * 8, nil
+}
+
+func appendSafeSlice16[T Integer16](dst []byte, v []T) []byte {
+	dst = slices.Grow(dst, len(v)*2)
+	for _, e := range v {
+		dst = appendSafeInteger16(dst, e)
+	}
+	return dst
+}
+
+func appendSafeSlice32[T Integer32](dst []byte, v []T) []byte {
+	dst = slices.Grow(dst, len(v)*4)
+	for _, e := range v {
+		dst = appendSafeInteger32(dst, e)
+	}
+	return dst
+}
+
+func appendSafeSlice64[T Integer64](dst []byte, v []T) []byte {
+	dst = slices.Grow(dst, len(v)*8)
+	for _, e := range v {
+		dst = appendSafeInteger64(dst, e)
+	}
+	return dst
+}
+
+func (Unsafe) MarshalInt8s(v []int8, bs []byte) (int, error) {
+	return marshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) UnmarshalInt8s(v []int8, bs []byte) (int, error) {
+	return unmarshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) AppendInt8s(dst []byte, v []int8) []byte {
+	return appendUnsafeSlice(dst, v)
+}
+
+func (Unsafe) MarshalInt16s(v []int16, bs []byte) (int, error) {
+	return marshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) UnmarshalInt16s(v []int16, bs []byte) (int, error) {
+	return unmarshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) AppendInt16s(dst []byte, v []int16) []byte {
+	return appendUnsafeSlice(dst, v)
+}
+
+func (Unsafe) MarshalInt32s(v []int32, bs []byte) (int, error) {
+	return marshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) UnmarshalInt32s(v []int32, bs []byte) (int, error) {
+	return unmarshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) AppendInt32s(dst []byte, v []int32) []byte {
+	return appendUnsafeSlice(dst, v)
+}
+
+func (Unsafe) MarshalInt64s(v []int64, bs []byte) (int, error) {
+	return marshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) UnmarshalInt64s(v []int64, bs []byte) (int, error) {
+	return unmarshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) AppendInt64s(dst []byte, v []int64) []byte {
+	return appendUnsafeSlice(dst, v)
+}
+
+func (Unsafe) MarshalUint8s(v []uint8, bs []byte) (int, error) {
+	return marshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) UnmarshalUint8s(v []uint8, bs []byte) (int, error) {
+	return unmarshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) AppendUint8s(dst []byte, v []uint8) []byte {
+	return appendUnsafeSlice(dst, v)
+}
+
+func (Unsafe) MarshalUint16s(v []uint16, bs []byte) (int, error) {
+	return marshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) UnmarshalUint16s(v []uint16, bs []byte) (int, error) {
+	return unmarshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) AppendUint16s(dst []byte, v []uint16) []byte {
+	return appendUnsafeSlice(dst, v)
+}
+
+func (Unsafe) MarshalUint32s(v []uint32, bs []byte) (int, error) {
+	return marshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) UnmarshalUint32s(v []uint32, bs []byte) (int, error) {
+	return unmarshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) AppendUint32s(dst []byte, v []uint32) []byte {
+	return appendUnsafeSlice(dst, v)
+}
+
+func (Unsafe) MarshalUint64s(v []uint64, bs []byte) (int, error) {
+	return marshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) UnmarshalUint64s(v []uint64, bs []byte) (int, error) {
+	return unmarshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) AppendUint64s(dst []byte, v []uint64) []byte {
+	return appendUnsafeSlice(dst, v)
+}
+
+func (Unsafe) MarshalFloat32s(v []float32, bs []byte) (int, error) {
+	return marshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) UnmarshalFloat32s(v []float32, bs []byte) (int, error) {
+	return unmarshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) AppendFloat32s(dst []byte, v []float32) []byte {
+	return appendUnsafeSlice(dst, v)
+}
+
+func (Unsafe) MarshalFloat64s(v []float64, bs []byte) (int, error) {
+	return marshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) UnmarshalFloat64s(v []float64, bs []byte) (int, error) {
+	return unmarshalUnsafeSlice(v, bs)
+}
+
+func (Unsafe) AppendFloat64s(dst []byte, v []float64) []byte {
+	return appendUnsafeSlice(dst, v)
+}
+
+func (Safe) MarshalInt8s(v []int8, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 1, bs); err != nil {
+		return 0, err
+	}
+	for i, e := range v {
+		bs[i] = byte(e)
+	}
+	return len(v), nil
+}
+
+func (Safe) UnmarshalInt8s(v []int8, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 1, bs); err != nil {
+		return 0, err
+	}
+	for i := range v {
+		v[i] = int8(bs[i])
+	}
+	return len(v), nil
+}
+
+func (Safe) AppendInt8s(dst []byte, v []int8) []byte {
+	dst = slices.Grow(dst, len(v))
+	for _, e := range v {
+		dst = append(dst, byte(e))
+	}
+	return dst
+}
+
+func (Safe) MarshalInt16s(v []int16, bs []byte) (int, error) {
+	return marshalSafeSlice16(v, bs)
+}
+
+func (Safe) UnmarshalInt16s(v []int16, bs []byte) (int, error) {
+	return unmarshalSafeSlice16(v, bs)
+}
+
+func (Safe) AppendInt16s(dst []byte, v []int16) []byte {
+	return appendSafeSlice16(dst, v)
+}
+
+func (Safe) MarshalInt32s(v []int32, bs []byte) (int, error) {
+	return marshalSafeSlice32(v, bs)
+}
+
+func (Safe) UnmarshalInt32s(v []int32, bs []byte) (int, error) {
+	return unmarshalSafeSlice32(v, bs)
+}
+
+func (Safe) AppendInt32s(dst []byte, v []int32) []byte {
+	return appendSafeSlice32(dst, v)
+}
+
+func (Safe) MarshalInt64s(v []int64, bs []byte) (int, error) {
+	return marshalSafeSlice64(v, bs)
+}
+
+func (Safe) UnmarshalInt64s(v []int64, bs []byte) (int, error) {
+	return unmarshalSafeSlice64(v, bs)
+}
+
+func (Safe) AppendInt64s(dst []byte, v []int64) []byte {
+	return appendSafeSlice64(dst, v)
+}
+
+func (Safe) MarshalUint8s(v []uint8, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 1, bs); err != nil {
+		return 0, err
+	}
+	return copy(bs, v), nil
+}
+
+func (Safe) UnmarshalUint8s(v []uint8, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 1, bs); err != nil {
+		return 0, err
+	}
+	return copy(v, bs), nil
+}
+
+func (Safe) AppendUint8s(dst []byte, v []uint8) []byte {
+	return append(dst, v...)
+}
+
+func (Safe) MarshalUint16s(v []uint16, bs []byte) (int, error) {
+	return marshalSafeSlice16(v, bs)
+}
+
+func (Safe) UnmarshalUint16s(v []uint16, bs []byte) (int, error) {
+	return unmarshalSafeSlice16(v, bs)
+}
+
+func (Safe) AppendUint16s(dst []byte, v []uint16) []byte {
+	return appendSafeSlice16(dst, v)
+}
+
+func (Safe) MarshalUint32s(v []uint32, bs []byte) (int, error) {
+	return marshalSafeSlice32(v, bs)
+}
+
+func (Safe) UnmarshalUint32s(v []uint32, bs []byte) (int, error) {
+	return unmarshalSafeSlice32(v, bs)
+}
+
+func (Safe) AppendUint32s(dst []byte, v []uint32) []byte {
+	return appendSafeSlice32(dst, v)
+}
+
+func (Safe) MarshalUint64s(v []uint64, bs []byte) (int, error) {
+	return marshalSafeSlice64(v, bs)
+}
+
+func (Safe) UnmarshalUint64s(v []uint64, bs []byte) (int, error) {
+	return unmarshalSafeSlice64(v, bs)
+}
+
+func (Safe) AppendUint64s(dst []byte, v []uint64) []byte {
+	return appendSafeSlice64(dst, v)
+}
+
+func (Safe) MarshalFloat32s(v []float32, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 4, bs); err != nil {
+		return 0, err
+	}
+	for i, e := range v {
+		marshalSafeInteger32(math.Float32bits(e), bs[i*4:])
+	}
+	return len(v) * 4, nil
+}
+
+func (Safe) UnmarshalFloat32s(v []float32, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 4, bs); err != nil {
+		return 0, err
+	}
+	for i := range v {
+		u, _, _ := unmarshalSafeInteger32[uint32](bs[i*4:])
+		v[i] = math.Float32frombits(u)
+	}
+	return len(v) * 4, nil
+}
+
+func (Safe) AppendFloat32s(dst []byte, v []float32) []byte {
+	dst = slices.Grow(dst, len(v)*4)
+	for _, e := range v {
+		dst = appendSafeInteger32(dst, math.Float32bits(e))
+	}
+	return dst
+}
+
+func (Safe) MarshalFloat64s(v []float64, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 8, bs); err != nil {
+		return 0, err
+	}
+	for i, e := range v {
+		marshalSafeInteger64(math.Float64bits(e), bs[i*8:])
+	}
+	return len(v) * 8, nil
+}
+
+func (Safe) UnmarshalFloat64s(v []float64, bs []byte) (int, error) {
+	if err := checkSafeSlice(len(v), 8, bs); err != nil {
+		return 0, err
+	}
+	for i := range v {
+		u, _, _ := unmarshalSafeInteger64[uint64](bs[i*8:])
+		v[i] = math.Float64frombits(u)
+	}
+	return len(v) * 8, nil
+}
+
+func (Safe) AppendFloat64s(dst []byte, v []float64) []byte {
+	dst = slices.Grow(dst, len(v)*8)
+	for _, e := range v {
+		dst = appendSafeInteger64(dst, math.Float64bits(e))
+	}
+	return dst
+}
@@ -0,0 +1,93 @@
+package gobin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testBulk checks the bulk methods of a codec for v against encoding every
+// element with marshal.
+func testBulk[T bulkNumber](t *testing.T, v []T,
+	marshal MarshallerFn[T],
+	marshalAll func([]T, []byte) (int, error),
+	unmarshalAll func([]T, []byte) (int, error),
+	appendAll func([]byte, []T) []byte,
+) {
+	r := require.New(t)
+	var want []byte
+	for _, e := range v {
+		var b [8]byte
+		n, err := marshal(e, b[:])
+		r.NoError(err)
+		want = append(want, b[:n]...)
+	}
+
+	bs := make([]byte, len(want))
+	n, err := marshalAll(v, bs)
+	r.NoError(err)
+	r.Equal(len(want), n)
+	r.Equal(want, bs)
+	r.Equal(append([]byte{1}, want...), appendAll([]byte{1}, v))
+
+	got := make([]T, len(v))
+	n, err = unmarshalAll(got, bs)
+	r.NoError(err)
+	r.Equal(len(want), n)
+	r.Equal(v, got)
+
+	_, err = marshalAll(v, bs[:len(bs)-1])
+	r.ErrorIs(err, ErrNotEnoughSpace)
+	_, err = unmarshalAll(got, bs[:len(bs)-1])
+	r.ErrorIs(err, ErrNotEnoughSpace)
+}
+
+func TestBulk(t *testing.T) {
+	s, u := Safe{}, Unsafe{}
+	i8 := []int8{0, 1, -1, math.MinInt8, math.MaxInt8}
+	i16 := []int16{0, 1, -1, math.MinInt16, math.MaxInt16}
+	i32 := []int32{0, 1, -1, math.MinInt32, math.MaxInt32}
+	i64 := []int64{0, 1, -1, math.MinInt64, math.MaxInt64}
+	u8 := []uint8{0, 1, math.MaxUint8}
+	u16 := []uint16{0, 1, math.MaxUint16}
+	u32 := []uint32{0, 1, math.MaxUint32}
+	u64 := []uint64{0, 1, math.MaxUint64}
+	f32 := []float32{0, 1.5, -2.25, math.MaxFloat32, float32(math.Inf(-1))}
+	f64 := []float64{0, 1.5, -2.25, math.MaxFloat64, math.Inf(1)}
+
+	t.Run("safe", func(t *testing.T) {
+		testBulk(t, i8, s.MarshalInt8, s.MarshalInt8s, s.UnmarshalInt8s, s.AppendInt8s)
+		testBulk(t, i16, s.MarshalInt16, s.MarshalInt16s, s.UnmarshalInt16s, s.AppendInt16s)
+		testBulk(t, i32, s.MarshalInt32, s.MarshalInt32s, s.UnmarshalInt32s, s.AppendInt32s)
+		testBulk(t, i64, s.MarshalInt64, s.MarshalInt64s, s.UnmarshalInt64s, s.AppendInt64s)
+		testBulk(t, u8, s.MarshalUint8, s.MarshalUint8s, s.UnmarshalUint8s, s.AppendUint8s)
+		testBulk(t, u16, s.MarshalUint16, s.MarshalUint16s, s.UnmarshalUint16s, s.AppendUint16s)
+		testBulk(t, u32, s.MarshalUint32, s.MarshalUint32s, s.UnmarshalUint32s, s.AppendUint32s)
+		testBulk(t, u64, s.MarshalUint64, s.MarshalUint64s, s.UnmarshalUint64s, s.AppendUint64s)
+		testBulk(t, f32, s.MarshalFloat32, s.MarshalFloat32s, s.UnmarshalFloat32s, s.AppendFloat32s)
+		testBulk(t, f64, s.MarshalFloat64, s.MarshalFloat64s, s.UnmarshalFloat64s, s.AppendFloat64s)
+	})
+	t.Run("unsafe", func(t *testing.T) {
+		testBulk(t, i8, u.MarshalInt8, u.MarshalInt8s, u.UnmarshalInt8s, u.AppendInt8s)
+		testBulk(t, i16, u.MarshalInt16, u.MarshalInt16s, u.UnmarshalInt16s, u.AppendInt16s)
+		testBulk(t, i32, u.MarshalInt32, u.MarshalInt32s, u.UnmarshalInt32s, u.AppendInt32s)
+		testBulk(t, i64, u.MarshalInt64, u.MarshalInt64s, u.UnmarshalInt64s, u.AppendInt64s)
+		testBulk(t, u8, u.MarshalUint8, u.MarshalUint8s, u.UnmarshalUint8s, u.AppendUint8s)
+		testBulk(t, u16, u.MarshalUint16, u.MarshalUint16s, u.UnmarshalUint16s, u.AppendUint16s)
+		testBulk(t, u32, u.MarshalUint32, u.MarshalUint32s, u.UnmarshalUint32s, u.AppendUint32s)
+		testBulk(t, u64, u.MarshalUint64, u.MarshalUint64s, u.UnmarshalUint64s, u.AppendUint64s)
+		testBulk(t, f32, u.MarshalFloat32, u.MarshalFloat32s, u.UnmarshalFloat32s, u.AppendFloat32s)
+		testBulk(t, f64, u.MarshalFloat64, u.MarshalFloat64s, u.UnmarshalFloat64s, u.AppendFloat64s)
+	})
+	t.Run("empty", func(t *testing.T) {
+		r := require.New(t)
+		n, err := u.MarshalFloat64s(nil, nil)
+		r.NoError(err)
+		r.Zero(n)
+		n, err = s.UnmarshalFloat64s(nil, nil)
+		r.NoError(err)
+		r.Zero(n)
+		r.Empty(u.AppendInt32s(nil, nil))
+	})
+}
@@ -0,0 +1,82 @@
+package gobin
+
+import (
+	"bytes"
+	"math"
+	"slices"
+)
+
+// Canonical encoding gives equal values identical bytes, so that encodings
+// can be signed, hashed or compared with bytes.Equal. It is the encoding of
+// MarshalCanonical and of the methods cmd/bingen generates for a type marked
+// gobin:canonical instead of gobin:binary. It differs from the default
+// encoding in that
+//
+//   - map entries are written in the order of their encoded keys,
+//   - every NaN is written as the NaN returned by math.NaN and -0.0 as 0.0,
+//   - an empty []byte is written as a nil one, as every other empty slice
+//     and map already is.
+//
+// Canonical encodings are decoded as any other. Values are equal when they
+// are after decoding: NaNs are equal to each other and times must have the
+// same offset as well as the same instant.
+
+// CanonicalFloat32 returns v with NaN and -0.0 normalised.
+func CanonicalFloat32(v float32) float32 {
+	switch {
+	case v != v:
+		return float32(math.NaN())
+	case v == 0:
+		return 0
+	}
+	return v
+}
+
+// CanonicalFloat64 returns v with NaN and -0.0 normalised.
+func CanonicalFloat64(v float64) float64 {
+	switch {
+	case math.IsNaN(v):
+		return math.NaN()
+	case v == 0:
+		return 0
+	}
+	return v
+}
+
+// CanonicalBytes returns nil for an empty v and v otherwise.
+func CanonicalBytes(v []byte) []byte {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// SortedKeys returns the keys of m in the order of their encoding with enc,
+// the order in which canonical encoding writes the entries of a map.
+func SortedKeys[M ~map[K]V, K comparable, V any](m M, enc func([]byte, K) []byte) []K {
+	var buf []byte
+	entries := make([]canonicalEntry[K], 0, len(m))
+	for k := range m {
+		start := len(buf)
+		buf = enc(buf, k)
+		entries = append(entries, canonicalEntry[K]{buf[start:], k})
+	}
+	sortEntries(entries)
+	keys := make([]K, len(entries))
+	for i, e := range entries {
+		keys[i] = e.v
+	}
+	return keys
+}
+
+// canonicalEntry is a map entry and the encoding it is sorted by.
+type canonicalEntry[T any] struct {
+	enc []byte
+	v   T
+}
+
+func sortEntries[T any](entries []canonicalEntry[T]) {
+	slices.SortFunc(entries, func(a, b canonicalEntry[T]) int {
+		return bytes.Compare(a.enc, b.enc)
+	})
+}
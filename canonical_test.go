@@ -0,0 +1,75 @@
+package gobin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type canonicalValue struct {
+	BigEndian
+	M map[string]float64
+	B []byte
+	F float32
+	N map[int16]map[string][]byte
+}
+
+func TestCanonical(t *testing.T) {
+	r := require.New(t)
+	negZero := math.Copysign(0, -1)
+	t.Run("floats", func(t *testing.T) {
+		r.Equal(math.Float64bits(math.NaN()), math.Float64bits(CanonicalFloat64(-math.NaN())))
+		r.Equal(math.Float64bits(0), math.Float64bits(CanonicalFloat64(negZero)))
+		r.Equal(1.5, CanonicalFloat64(1.5))
+		r.Equal(math.Float32bits(float32(math.NaN())), math.Float32bits(CanonicalFloat32(float32(-math.NaN()))))
+		r.Equal(uint32(0), math.Float32bits(CanonicalFloat32(float32(negZero))))
+	})
+	t.Run("bytes", func(t *testing.T) {
+		r.Nil(CanonicalBytes([]byte{}))
+		r.Equal([]byte("a"), CanonicalBytes([]byte("a")))
+	})
+	t.Run("should sort keys by their encoding", func(t *testing.T) {
+		m := map[int32]bool{-1: true, 1: true, 256: true, 2: false}
+		r.Equal([]int32{1, 2, 256, -1}, SortedKeys(m, BigEndian{}.AppendInt32))
+		r.Equal([]int32{256, 1, 2, -1}, SortedKeys(m, Safe{}.AppendInt32))
+		r.Empty(SortedKeys(map[int32]bool(nil), Safe{}.AppendInt32))
+	})
+	t.Run("equal values should have identical encodings", func(t *testing.T) {
+		mk := func() canonicalValue {
+			v := canonicalValue{
+				M: map[string]float64{"z": negZero, "a": math.NaN(), "m": 1},
+				F: float32(negZero),
+				N: map[int16]map[string][]byte{7: {"x": nil, "y": {}}, -7: nil, 0: {}},
+			}
+			for j := 0; j < 10; j++ {
+				v.M[string(rune('b'+j))] = float64(j)
+			}
+			return v
+		}
+		want, err := MarshalCanonical(mk())
+		r.NoError(err)
+		for i := 0; i < 20; i++ {
+			v := mk()
+			if i%2 == 0 {
+				v.B = []byte{}
+			}
+			bs, err := MarshalCanonical(&v)
+			r.NoError(err)
+			r.Equal(want, bs)
+		}
+		var v canonicalValue
+		r.NoError(Unmarshal(want, &v))
+		r.Nil(v.B)
+		r.True(math.IsNaN(v.M["a"]))
+		r.Equal(uint64(0), math.Float64bits(v.M["z"]))
+	})
+	t.Run("should match Marshal otherwise", func(t *testing.T) {
+		v := &canonicalValue{M: map[string]float64{"a": 1}, B: []byte("b"), F: 2}
+		bs, err := Marshal(v)
+		r.NoError(err)
+		cbs, err := MarshalCanonical(v)
+		r.NoError(err)
+		r.Equal(bs, cbs)
+	})
+}
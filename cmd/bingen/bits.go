@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// bitsTag returns the width of a bit field tagged gobin:"bits=N", 0 if tag,
+// the value of its gobin key, is not a bits tag, or -1 if N is not a positive
+// number.
+func bitsTag(tag string) int {
+	s, ok := strings.CutPrefix(tag, "bits=")
+	if !ok {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return -1
+	}
+	return n
+}
+
+// bitsOf returns the widest bit field of type ft, 0 if ft is not an integer,
+// and whether it is signed. int and uint are 64 bits wide on the wire.
+func bitsOf(ft *FieldType) (widest int, signed bool) {
+	if ft.Kind != "basic" {
+		return 0, false
+	}
+	switch ft.Name {
+	case "int", "int64":
+		return 64, true
+	case "int32":
+		return 32, true
+	case "int16":
+		return 16, true
+	case "int8":
+		return 8, true
+	case "uint", "uint64":
+		return 64, false
+	case "uint32":
+		return 32, false
+	case "uint16":
+		return 16, false
+	case "uint8", "byte":
+		return 8, false
+	}
+	return 0, false
+}
+
+// checkBits returns an error if a bit field of si is not an integer or is
+// wider than its type.
+func checkBits(si *StructInfo) error {
+	for _, sf := range si.Fields {
+		if sf.Bits == 0 {
+			continue
+		}
+		if widest, _ := bitsOf(sf.Type); sf.Bits < 1 || sf.Bits > widest {
+			return fmt.Errorf("%s.%s: invalid bit field width %d for %s", si.Name, sf.Name, sf.Bits, getTypeString(sf.Type.Expr))
+		}
+	}
+	return nil
+}
+
+// bitsRun returns the number of consecutive bit fields of si from the i-th
+// one, or 0.
+func bitsRun(si *StructInfo, i int) int {
+	n := 0
+	for i+n < len(si.Fields) && si.Fields[i+n].Bits > 0 {
+		n++
+	}
+	return n
+}
+
+// hasBits reports whether si has a bit field.
+func hasBits(si *StructInfo) bool {
+	for _, sf := range si.Fields {
+		if sf.Bits > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// bitsSize returns the encoded size of a run of bit fields.
+func bitsSize(fields []StructField) int {
+	n := 0
+	for _, sf := range fields {
+		n += sf.Bits
+	}
+	return (n + 7) / 8
+}
+
+func sizeBits(out io.Writer, fields []StructField) {
+	fmt.Fprintf(out, "size += %d", bitsSize(fields))
+	fmt.Fprintln(out)
+}
+
+// writeBits writes the calls of a BitWriter named bw writing fields, setting
+// err with assign, and failing with ret, the values returned by the function
+// besides the error, on overflow.
+func writeBits(out io.Writer, fields []StructField, assign, ret string) {
+	for _, sf := range fields {
+		if _, signed := bitsOf(sf.Type); signed {
+			fmt.Fprintf(out, "if err %s bw.WriteSignedBits(int64(o.%s), %d); err != nil {", assign, sf.Name, sf.Bits)
+		} else {
+			fmt.Fprintf(out, "if err %s bw.WriteBits(uint64(o.%s), %d); err != nil {", assign, sf.Name, sf.Bits)
+		}
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "return %s, fmt.Errorf(\"%s: %%w\", err)", ret, sf.Name)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "}")
+	}
+}
+
+// marshalBits writes a run of bit fields after checking that its bytes fit,
+// so that the BitWriter appends in place.
+func marshalBits(out io.Writer, fields []StructField) {
+	size := bitsSize(fields)
+	fmt.Fprintf(out, "if len(data)-offset < %d {", size)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "return 0, gobin.ErrNotEnoughSpace")
+	fmt.Fprintln(out, "}")
+	fmt.Fprintln(out, "{")
+	fmt.Fprintln(out, "bw := gobin.NewBitWriter(data[offset:offset])")
+	writeBits(out, fields, "=", "0")
+	fmt.Fprintln(out, "n = len(bw.Bytes())")
+	fmt.Fprintln(out, "}")
+	fmt.Fprintln(out, "offset += n")
+}
+
+func appendBits(out io.Writer, fields []StructField) {
+	fmt.Fprintln(out, "{")
+	fmt.Fprintln(out, "bw := gobin.NewBitWriter(dst)")
+	writeBits(out, fields, ":=", "nil")
+	fmt.Fprintln(out, "dst = bw.Bytes()")
+	fmt.Fprintln(out, "}")
+}
+
+// writeBitsField writes a run of bit fields with a Writer named w.
+func writeBitsField(out io.Writer, fields []StructField) {
+	fmt.Fprintf(out, "w.WriteFunc(%d, func(data []byte) (int, error) {", bitsSize(fields))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "bw := gobin.NewBitWriter(data[:0])")
+	writeBits(out, fields, ":=", "0")
+	fmt.Fprintln(out, "return len(bw.Bytes()), nil")
+	fmt.Fprintln(out, "})")
+}
+
+// unmarshalBits reads a run of bit fields, reporting errors at the first
+// field of the run.
+func unmarshalBits(out io.Writer, fields []StructField) {
+	fmt.Fprintf(out, "r.Field(%q)", fields[0].Name)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "r.ReadBitFields(func(br *gobin.BitReader) {")
+	for _, sf := range fields {
+		if _, signed := bitsOf(sf.Type); signed {
+			fmt.Fprintf(out, "o.%s = %s(br.ReadSignedBits(%d))", sf.Name, getTypeString(sf.Type.Expr), sf.Bits)
+		} else {
+			fmt.Fprintf(out, "o.%s = %s(br.ReadBits(%d))", sf.Name, getTypeString(sf.Type.Expr), sf.Bits)
+		}
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintln(out, "})")
+}
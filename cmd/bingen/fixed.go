@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// fixedSize returns the encoded size of ft if it is the same for every value
+// with the fixed width codecs, or -1: strings, byte slices, slices, maps and
+// pointers have a length or isnil prefix.
+func fixedSize(ft *FieldType) int {
+	switch ft.Kind {
+	case "basic":
+		bt := basicTypes.Get(ft.Name)
+		if bt == nil || bt.Type == "String" || bt.Type == "Bytes" {
+			return -1
+		}
+		return bt.Size
+	case "array":
+		if n := fixedSize(ft.ElemType); n >= 0 {
+			return n * ft.Size
+		}
+	case "struct":
+		n := 0
+		for _, sf := range ft.Fields {
+			m := fixedSize(sf.Type)
+			if m < 0 {
+				return -1
+			}
+			n += m
+		}
+		return n
+	}
+	return -1
+}
+
+// structSize returns the encoded size of the type of si if it only has fixed
+// width fields and embeds a fixed width codec, or -1.
+func structSize(si *StructInfo) int {
+	switch si.Codec {
+	case "Safe", "Unsafe", "BigEndian":
+	default:
+		return -1
+	}
+	size := 0
+	for i := 0; i < len(si.Fields); i++ {
+		if n := packedRun(si, i); n > 0 {
+			size += (n + 7) / 8
+			i += n - 1
+			continue
+		}
+		if n := bitsRun(si, i); n > 0 {
+			size += bitsSize(si.Fields[i : i+n])
+			i += n - 1
+			continue
+		}
+		n := fixedSize(si.Fields[i].Type)
+		if n < 0 {
+			return -1
+		}
+		size += n
+	}
+	return size
+}
+
+// sizeConst returns the name of the constant holding the size of a fixed
+// size type.
+func sizeConst(si *StructInfo) string {
+	return si.Name + "Size"
+}
+
+// storable reports whether Unsafe encodes values of ft as their memory, so
+// that they can be copied with a single store. Bools are checked on decoding,
+// so only arrays of numbers are stored at once. int and uint are 32 bits wide
+// on some platforms, times and GUIDs have encodings of their own.
+func storable(ft *FieldType) bool {
+	switch ft.Kind {
+	case "basic":
+		bt := basicTypes.Get(ft.Name)
+		if bt == nil {
+			return false
+		}
+		switch bt.Type {
+		case "Bool", "Int8", "Int16", "Int32", "Int64", "Uint8", "Uint16", "Uint32", "Uint64",
+			"Float32", "Float64", "Byte", "Duration":
+			return true
+		}
+	case "array":
+		return ft.ElemType.Kind != "struct" && ft.ElemType.Name != "bool" && storable(ft.ElemType)
+	case "struct":
+		for _, sf := range ft.Fields {
+			if !storable(sf.Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// straightLine reports whether the type of si is encoded with a single bounds
+// check and straight-line stores: a fixed size type embedding gobin.Unsafe,
+// with storable fields. Canonical types normalise their floats, packed types
+// pack their bools, and bit fields are not byte aligned.
+func straightLine(si *StructInfo) bool {
+	return si.Codec == "Unsafe" && !si.Canonical && !si.Packed && !hasBits(si) && structSize(si) >= 0 &&
+		storable(&FieldType{Kind: "struct", Fields: si.Fields})
+}
+
+// storeField writes the stores of name at offset off of data, returning the
+// offset of the next field.
+func storeField(out io.Writer, ft *FieldType, name string, off int) int {
+	if ft.Kind == "struct" {
+		for _, sf := range ft.Fields {
+			off = storeField(out, sf.Type, name+"."+sf.Name, off)
+		}
+		return off
+	}
+	fmt.Fprintf(out, "*(*%s)(unsafe.Pointer(&data[%d])) = %s", getTypeString(ft.Expr), off, name)
+	fmt.Fprintln(out)
+	return off + fixedSize(ft)
+}
+
+// loadField writes the loads of name from offset off of data, returning the
+// offset of the next field. Bools other than 0 and 1 are rejected as by
+// Unsafe.UnmarshalBool.
+func loadField(out io.Writer, ft *FieldType, name, path string, off int) int {
+	if ft.Kind == "struct" {
+		for _, sf := range ft.Fields {
+			off = loadField(out, sf.Type, name+"."+sf.Name, path+"."+sf.Name, off)
+		}
+		return off
+	}
+	if ft.Kind == "basic" && ft.Name == "bool" {
+		fmt.Fprintf(out, "if data[%d] > 1 {", off)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "return 0, &gobin.DecodeError{Offset: %d, Field: %q, Type: \"bool\", Err: gobin.ErrInvalidBool}", off, path)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "}")
+	}
+	fmt.Fprintf(out, "%s = *(*%s)(unsafe.Pointer(&data[%d]))", name, getTypeString(ft.Expr), off)
+	fmt.Fprintln(out)
+	return off + fixedSize(ft)
+}
+
+// generateStraightMarshal writes MarshalTo and MarshalAppend of a
+// straightLine type.
+func generateStraightMarshal(out io.Writer, si *StructInfo) {
+	fmt.Fprintf(out, "// MarshalTo encodes o as conform encoding.BinaryMarshaler.")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "func (o *%s) MarshalTo(data []byte) (int, error) {", si.Name)
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "if len(data) < %s {", sizeConst(si))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "return 0, gobin.ErrNotEnoughSpace")
+	fmt.Fprintln(out, "}")
+	off := 0
+	for _, sf := range si.Fields {
+		fmt.Fprintf(out, "// %s", sf.Name)
+		fmt.Fprintln(out)
+		off = storeField(out, sf.Type, "o."+sf.Name, off)
+	}
+	fmt.Fprintf(out, "return %s, nil", sizeConst(si))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "}")
+
+	fmt.Fprintf(out, "// MarshalAppend appends the encoding of o to dst.")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "func (o *%s) MarshalAppend(dst []byte) ([]byte, error) {", si.Name)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "n := len(dst)")
+	fmt.Fprintf(out, "dst = append(dst, make([]byte, %s)...)", sizeConst(si))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "_, err := o.MarshalTo(dst[n:])")
+	fmt.Fprintln(out, "return dst, err")
+	fmt.Fprintln(out, "}")
+}
+
+// generateStraightUnmarshal writes UnmarshalFrom and UnmarshalReader of a
+// straightLine type.
+func generateStraightUnmarshal(out io.Writer, si *StructInfo) {
+	fmt.Fprintf(out, "// UnmarshalFrom decodes o as conform encoding.BinaryUnmarshaler.")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "func (o *%s) UnmarshalFrom(data []byte) (int, error) {", si.Name)
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "if len(data) < %s {", sizeConst(si))
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "return 0, &gobin.DecodeError{Type: %q, Err: gobin.ErrNotEnoughSpace}", si.Name)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "}")
+	off := 0
+	for _, sf := range si.Fields {
+		fmt.Fprintf(out, "// %s", sf.Name)
+		fmt.Fprintln(out)
+		off = loadField(out, sf.Type, "o."+sf.Name, sf.Name, off)
+	}
+	fmt.Fprintf(out, "return %s, nil", sizeConst(si))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "}")
+	fmt.Fprintln(out)
+
+	fmt.Fprintf(out, "// UnmarshalReader decodes o from r, as conform gobin.ReaderUnmarshaler.")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "func (o *%s) UnmarshalReader(r *gobin.Reader) {", si.Name)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "r.ReadFunc(o.UnmarshalFrom)")
+	fmt.Fprintln(out, "}")
+}
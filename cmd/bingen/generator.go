@@ -4,17 +4,25 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/format"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"io"
 	"os"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
 const (
 	structComment     = "gobin:binary"
 	structSkipComment = "gobin:skip"
-	defaultLength     = 8 //int
+	// structCanonicalComment marks a type like structComment, encoding it
+	// canonically.
+	structCanonicalComment = "gobin:canonical"
+	defaultLength          = 8 //int
 )
 
 type Generator struct {
@@ -29,7 +37,7 @@ type Generator struct {
 	typeSpecs map[string]ast.Expr
 }
 
-func (p *Generator) needType(comments *ast.CommentGroup) (skip, explicit bool) {
+func (p *Generator) needType(comments *ast.CommentGroup) (skip, explicit, canonical bool) {
 	if comments == nil {
 		return
 	}
@@ -52,10 +60,13 @@ func (p *Generator) needType(comments *ast.CommentGroup) (skip, explicit bool) {
 			comment = strings.TrimSpace(comment)
 
 			if strings.HasPrefix(comment, structSkipComment) {
-				return true, false
+				return true, false, false
 			}
 			if strings.HasPrefix(comment, structComment) {
-				return false, true
+				return false, true, false
+			}
+			if strings.HasPrefix(comment, structCanonicalComment) {
+				return false, true, true
 			}
 		}
 	}
@@ -78,7 +89,7 @@ func (v *visitor) Visit(n ast.Node) (w ast.Visitor) {
 		return v
 
 	case *ast.GenDecl:
-		skip, explicit := v.needType(n.Doc)
+		skip, explicit, _ := v.needType(n.Doc)
 
 		if skip || explicit {
 			for _, nc := range n.Specs {
@@ -91,7 +102,7 @@ func (v *visitor) Visit(n ast.Node) (w ast.Visitor) {
 
 		return v
 	case *ast.TypeSpec:
-		skip, explicit := v.needType(n.Doc)
+		skip, explicit, canonical := v.needType(n.Doc)
 		if skip {
 			return nil
 		}
@@ -100,18 +111,26 @@ func (v *visitor) Visit(n ast.Node) (w ast.Visitor) {
 		}
 
 		v.name = n.Name.String()
-		v.typeSpecs[n.Name.Name] = n.Type
 		if structType, ok := n.Type.(*ast.StructType); ok {
 			structInfo := &StructInfo{
-				Name:   n.Name.Name,
-				Fields: make([]StructField, 0, len(structType.Fields.List)),
+				Name:      n.Name.Name,
+				Canonical: canonical,
+				Fields:    make([]StructField, 0, len(structType.Fields.List)),
 			}
 
 			for _, field := range structType.Fields.List {
+				if len(field.Names) == 0 {
+					if codec := embeddedCodec(field.Type); codec != "" {
+						structInfo.Codec = codec
+						structInfo.Packed = fieldTag(field, "gobin") == "packed"
+					}
+					continue
+				}
 				for _, name := range field.Names {
 					structInfo.Fields = append(structInfo.Fields, StructField{
 						Name: name.Name,
 						Type: parseFieldType(field.Type, v.typeSpecs, 0),
+						Bits: bitsTag(fieldTag(field, "gobin")),
 					})
 				}
 			}
@@ -124,6 +143,7 @@ func (v *visitor) Visit(n ast.Node) (w ast.Visitor) {
 func (g *Generator) Parse(fname string, isDir bool) error {
 	g.typeSpecs = make(map[string]ast.Expr)
 	fset := token.NewFileSet()
+	var nodes []ast.Node
 	if isDir {
 		packages, err := parser.ParseDir(fset, fname, excludeTestFiles, parser.ParseComments)
 		if err != nil {
@@ -131,105 +151,420 @@ func (g *Generator) Parse(fname string, isDir bool) error {
 		}
 
 		for _, pckg := range packages {
-			ast.Walk(&visitor{Generator: g}, pckg)
+			nodes = append(nodes, pckg)
 		}
 	} else {
 		f, err := parser.ParseFile(fset, fname, nil, parser.ParseComments)
 		if err != nil {
 			return err
 		}
-
-		ast.Walk(&visitor{Generator: g}, f)
+		nodes = append(nodes, f)
+	}
+	// collect every type first, a field may use a type declared after it
+	for _, n := range nodes {
+		ast.Inspect(n, func(n ast.Node) bool {
+			if ts, ok := n.(*ast.TypeSpec); ok {
+				g.typeSpecs[ts.Name.Name] = ts.Type
+			}
+			return true
+		})
+	}
+	for _, n := range nodes {
+		ast.Walk(&visitor{Generator: g}, n)
 	}
 	return nil
 }
 
 func (g *Generator) Run() error {
+	code, err := g.Generate()
+	if err != nil {
+		return err
+	}
+	header := fmt.Sprintf(generatedHeader, strings.Join(os.Args[1:], " "))
+	return os.WriteFile(g.OutName, append([]byte(header), code...), 0o644)
+}
+
+// generatedHeader is the first line of generated files, recording the
+// arguments of the command.
+const generatedHeader = "// Code generated by \"gobingen %s\"; DO NOT EDIT.\n"
+
+// checkEmpty returns an error if a field of si is a slice or map of values
+// encoded in no bytes, such as struct{}, whose length gobin.Reader could not
+// check against the bytes left. gobin.Marshal rejects them too.
+func checkEmpty(si *StructInfo) error {
+	for _, sf := range si.Fields {
+		if ft := emptyCollection(sf.Type); ft != nil {
+			return fmt.Errorf("%s.%s: unsupported %s, its elements are encoded in no bytes", si.Name, sf.Name, types.ExprString(ft.Expr))
+		}
+	}
+	return nil
+}
+
+// emptyCollection returns the first slice or map within ft whose elements are
+// encoded in no bytes, or nil.
+func emptyCollection(ft *FieldType) *FieldType {
+	switch ft.Kind {
+	case "slice":
+		if encodesEmpty(ft.ElemType) {
+			return ft
+		}
+	case "map":
+		if encodesEmpty(ft.KeyType) && encodesEmpty(ft.ElemType) {
+			return ft
+		}
+		if c := emptyCollection(ft.KeyType); c != nil {
+			return c
+		}
+	case "struct":
+		for _, sf := range ft.Fields {
+			if c := emptyCollection(sf.Type); c != nil {
+				return c
+			}
+		}
+		return nil
+	}
+	if ft.ElemType != nil {
+		return emptyCollection(ft.ElemType)
+	}
+	return nil
+}
+
+// encodesEmpty reports whether values of ft are encoded in no bytes, as a
+// struct{} or a [0]int is.
+func encodesEmpty(ft *FieldType) bool {
+	switch ft.Kind {
+	case "array":
+		return ft.Size == 0 || encodesEmpty(ft.ElemType)
+	case "struct":
+		for _, sf := range ft.Fields {
+			if !encodesEmpty(sf.Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Generate parses GoFile and returns the generated code, formatted, without
+// the generatedHeader line.
+func (g *Generator) Generate() ([]byte, error) {
 	var output = &bytes.Buffer{}
 	if err := g.Parse(g.GoFile, g.IsDir); err != nil {
-		return err
+		return nil, err
+	}
+	for _, si := range g.StructInfos {
+		if err := checkBits(si); err != nil {
+			return nil, err
+		}
+		if err := checkEmpty(si); err != nil {
+			return nil, err
+		}
 	}
 
 	code, err := g.GenerateSize()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	output.Write(code)
 	code, err = g.GenerateMarshal()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	output.Write(code)
 	code, err = g.GenerateUnmarshal()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	output.Write(code)
 
-	f, err := os.Create(g.OutName)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	fmt.Fprintf(f, "// Code generated by \"gobingen %s\"; DO NOT EDIT.\n", strings.Join(os.Args[1:], " "))
+	f := &bytes.Buffer{}
 	fmt.Fprintln(f, "package ", g.PkgName)
 
 	if len(g.Types) > 0 {
 		fmt.Fprintln(f)
 		fmt.Fprintln(f, "import (")
 		fmt.Fprintln(f, `  "fmt"`)
+		if usesTime.Match(output.Bytes()) {
+			fmt.Fprintln(f, `  "time"`)
+		}
+		if usesUnsafe.Match(output.Bytes()) {
+			fmt.Fprintln(f, `  "unsafe"`)
+		}
 		fmt.Fprintln(f, `  "github.com/millken/gobin"`)
 		fmt.Fprintln(f, ")")
 	}
 	f.Write(output.Bytes())
-	return nil
+	return format.Source(f.Bytes())
+}
+
+// usesTime matches generated code referring to the time package, e.g. in
+// gobin.MakeSlice[[]time.Time].
+var usesTime = regexp.MustCompile(`[^.\w]time\.`)
+
+// usesUnsafe matches generated code of straightLine types.
+var usesUnsafe = regexp.MustCompile(`[^.\w]unsafe\.`)
+
+// embeddedCodec returns the name of the gobin codec embedded by a struct,
+// e.g. "Safe" for an embedded gobin.Safe.
+func embeddedCodec(expr ast.Expr) string {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "gobin" {
+		return ""
+	}
+	return sel.Sel.Name
+}
+
+// fieldTag returns the value of key in the tag of field.
+func fieldTag(field *ast.Field, key string) string {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(tag).Get(key)
+}
+
+// sizeBasic writes the size of a basic value. The size of fixed width codecs
+// is known from basicTypes, variable width codecs are asked for it.
+func sizeBasic(out io.Writer, si *StructInfo, bt *baseType, name string) {
+	name = encodeValue(si, bt, name)
+	if si.Codec == "Varint" {
+		fmt.Fprintf(out, "size += o.Size%s(%s)", bt.Type, name)
+		fmt.Fprintln(out)
+		return
+	}
+	switch {
+	case bt.Name == "[]byte" && si.Codec == "Unsafe":
+		fmt.Fprintf(out, "size += %d + len(%s)", defaultLength, name)
+	case bt.Name == "[]byte":
+		// a nil slice is encoded as the isnil flag alone
+		fmt.Fprintf(out, "size += 1")
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "if %s != nil {", name)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "size += %d + len(%s)", defaultLength, name)
+		fmt.Fprintln(out)
+		fmt.Fprint(out, "}")
+	case bt.Name == "string":
+		fmt.Fprintf(out, "size += %d + len(%s)", bt.Size, name)
+	default:
+		fmt.Fprintf(out, "size += %d", bt.Size)
+	}
+	fmt.Fprintln(out)
+}
+
+// sizeLength writes the size of the length prefix of a slice or map.
+func sizeLength(out io.Writer, si *StructInfo, name string) {
+	if si.Codec == "Varint" {
+		fmt.Fprintf(out, "size += o.SizeInt(len(%s))", name)
+	} else {
+		fmt.Fprintf(out, "size += %d", defaultLength)
+	}
+	fmt.Fprintln(out)
 }
 
-func sizeMapKey(out io.Writer, ft *FieldType, name string) {
+func sizeMapKey(out io.Writer, si *StructInfo, ft *FieldType, name string) {
 	switch ft.Kind {
 	case "basic":
 		bt := basicTypes.Get(ft.Name)
 		if bt == nil {
 			panic("unsupported basic type :" + ft.Name)
 		}
-		fmt.Fprintf(out, "size += %d", bt.Size)
+		sizeBasic(out, si, bt, name)
+	default:
+		panic("unsupported type :" + ft.Kind)
+	}
+}
+
+// encodeValue returns the expression passing name to the codec method of bt.
+// GUID types are converted to gobin.GUID, canonical types normalise floats
+// and empty byte slices.
+func encodeValue(si *StructInfo, bt *baseType, name string) string {
+	switch {
+	case bt.Type == "GUID":
+		return "gobin.GUID(" + name + ")"
+	case !si.Canonical:
+		return name
+	case bt.Type == "Float32", bt.Type == "Float64", bt.Type == "Bytes":
+		return "gobin.Canonical" + bt.Type + "(" + name + ")"
+	}
+	return name
+}
+
+// collectionFn returns the type of the codec methods encoding values of ft,
+// e.g. Int32, for the slice and map helpers of gobin, or "" if ft is not a
+// basic type passed to them as is.
+func collectionFn(si *StructInfo, ft *FieldType) string {
+	if ft.Kind != "basic" || ft.Expr == nil || getTypeString(ft.Expr) != ft.Name {
+		return ""
+	}
+	bt := basicTypes.Get(ft.Name)
+	if bt == nil || encodeValue(si, bt, "v") != "v" {
+		return ""
+	}
+	return bt.Type
+}
+
+// mapFns returns the types of the codec methods encoding the keys and values
+// of map ft with gobin.MarshalMap, or "" if they are encoded one by one.
+// Canonical types write their entries in order.
+func mapFns(si *StructInfo, ft *FieldType) (key, value string) {
+	if si.Canonical {
+		return "", ""
+	}
+	key, value = collectionFn(si, ft.KeyType), collectionFn(si, ft.ElemType)
+	if key == "" || value == "" {
+		return "", ""
+	}
+	return key, value
+}
+
+// rangeMap writes the head of a loop over the entries k, v of map name. The
+// entries of canonical types are visited in the order of their encoded keys.
+func rangeMap(out io.Writer, si *StructInfo, ft *FieldType, name string) {
+	if !si.Canonical {
+		fmt.Fprintf(out, "for k, v := range %s {", name)
 		fmt.Fprintln(out)
-		if ft.Name == "string" || ft.Name == "[]byte" {
-			fmt.Fprintf(out, "size += len(%s)", name)
+		return
+	}
+	bt := basicTypes.Get(ft.KeyType.Name)
+	if bt == nil {
+		panic("unsupported basic type :" + ft.KeyType.Name)
+	}
+	enc := "o.Append" + bt.Type
+	if key := encodeValue(si, bt, "k"); key != "k" {
+		enc = fmt.Sprintf("func(dst []byte, k %s) []byte { return o.Append%s(dst, %s) }",
+			getTypeString(ft.KeyType.Expr), bt.Type, key)
+	}
+	fmt.Fprintf(out, "for _, k := range gobin.SortedKeys(%s, %s) {", name, enc)
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "v := %s[k]", name)
+	fmt.Fprintln(out)
+}
+
+// decodeValue returns the expression reading a value of ft from r. Reader
+// reads a byte with ReadUint8, ReadByte being reserved for io.ByteReader.
+func decodeValue(ft *FieldType, bt *baseType) string {
+	read := "r.Read" + bt.Type + "()"
+	switch bt.Type {
+	case "Byte":
+		read = "r.ReadUint8()"
+	case "GUID":
+		return getTypeString(ft.Expr) + "(" + read + ")"
+	}
+	return read
+}
+
+// bulkType returns the element type of a slice or array of ft encoded with a
+// single call to the bulk methods of Safe and Unsafe, e.g. MarshalFloat64s, or
+// nil if its elements are encoded one by one. Canonical types encode floats
+// one by one to normalise them.
+func bulkType(si *StructInfo, ft *FieldType) *baseType {
+	if si.Codec != "Safe" && si.Codec != "Unsafe" || ft.ElemType.Kind != "basic" {
+		return nil
+	}
+	bt := basicTypes.Get(ft.ElemType.Name)
+	if bt == nil {
+		return nil
+	}
+	switch bt.Type {
+	case "Byte":
+		bt.Type = "Uint8"
+	case "Int8", "Int16", "Int32", "Int64", "Uint8", "Uint16", "Uint32", "Uint64":
+	case "Float32", "Float64":
+		if si.Canonical {
+			return nil
 		}
 	default:
-		panic("unsupported type :" + ft.Kind)
+		return nil
 	}
+	return bt
 }
 
-func sizeField(out io.Writer, ft *FieldType, name string) {
+// bulkValue returns the expression passing the elements of name to a bulk
+// method: arrays are sliced.
+func bulkValue(ft *FieldType, name string) string {
+	if ft.Kind == "array" {
+		return name + "[:]"
+	}
+	return name
+}
+
+// deref returns the expression of the value name points to.
+func deref(name string) string {
+	return "(*" + name + ")"
+}
+
+func sizeField(out io.Writer, si *StructInfo, ft *FieldType, name string) {
 	switch ft.Kind {
 	case "basic":
 		bt := basicTypes.Get(ft.Name)
 		if bt == nil {
 			panic("unsupported basic type :" + ft.Name)
 		}
-		fmt.Fprintf(out, "size += %d", bt.Size)
-		fmt.Fprintln(out)
-		if ft.Name == "string" || ft.Name == "[]byte" {
-			fmt.Fprintf(out, "size += len(%s)", name)
-		}
+		sizeBasic(out, si, bt, name)
 
 	case "slice":
-		fmt.Fprintf(out, "size += %d", defaultLength)
-		fmt.Fprintln(out)
+		if fn := collectionFn(si, ft.ElemType); fn != "" && si.Codec == "Varint" {
+			fmt.Fprintf(out, "size += gobin.SizeSlice(o.SizeInt, o.Size%s, %s)", fn, name)
+			fmt.Fprintln(out)
+			break
+		}
+		sizeLength(out, si, name)
+		if bt := bulkType(si, ft); bt != nil {
+			fmt.Fprintf(out, "size += len(%s) * %d", name, bt.Size)
+			fmt.Fprintln(out)
+			break
+		}
 		fmt.Fprintf(out, "for _, v := range %s {", name)
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "_ = v")
 		fmt.Fprintln(out)
-		sizeField(out, ft.ElemType, "v")
+		sizeField(out, si, ft.ElemType, "v")
 		fmt.Fprintln(out, "}")
 	case "array":
+		if bt := bulkType(si, ft); bt != nil {
+			fmt.Fprintf(out, "size += len(%s) * %d", name, bt.Size)
+			fmt.Fprintln(out)
+			break
+		}
+		fmt.Fprintf(out, "for _, v := range %s {", name)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "_ = v")
+		fmt.Fprintln(out)
+		sizeField(out, si, ft.ElemType, "v")
+		fmt.Fprintln(out, "}")
+	case "pointer":
+		fmt.Fprintln(out, "size += 1 // isnil")
+		fmt.Fprintf(out, "if %s != nil {", name)
+		fmt.Fprintln(out)
+		sizeField(out, si, ft.ElemType, deref(name))
+		fmt.Fprintln(out, "}")
 	case "struct":
 		for _, sf := range ft.Fields {
-			sizeField(out, sf.Type, name+"."+sf.Name)
+			sizeField(out, si, sf.Type, name+"."+sf.Name)
 		}
 	case "map":
+		if k, v := mapFns(si, ft); k != "" && si.Codec == "Varint" {
+			fmt.Fprintf(out, "size += gobin.SizeMap(o.SizeInt, o.Size%s, o.Size%s, %s)", k, v, name)
+			fmt.Fprintln(out)
+			break
+		}
+		sizeLength(out, si, name)
+		// the size does not depend on the order of the entries
+		fmt.Fprintf(out, "for k, v := range %s {", name)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "_, _ = k, v")
+		sizeMapKey(out, si, ft.KeyType, "k")
+		fmt.Fprintln(out)
+		sizeField(out, si, ft.ElemType, "v")
+		fmt.Fprintln(out, "}")
 	default:
 		panic("unsupported type :" + ft.Kind)
 	}
@@ -240,52 +575,46 @@ func (g *Generator) GenerateSize() ([]byte, error) {
 	var out = &bytes.Buffer{}
 
 	for _, si := range g.StructInfos {
+		if n := structSize(si); n >= 0 {
+			fmt.Fprintf(out, "// %s is the encoded size of %s, which only has fixed width fields.", sizeConst(si), si.Name)
+			fmt.Fprintln(out)
+			fmt.Fprintf(out, "const %s = %d", sizeConst(si), n)
+			fmt.Fprintln(out)
+			fmt.Fprintln(out)
+			fmt.Fprintf(out, "// SizeBinary returns the size of the serialized object")
+			fmt.Fprintln(out)
+			fmt.Fprintf(out, "func (o *%s) SizeBinary() int {", si.Name)
+			fmt.Fprintln(out)
+			fmt.Fprintf(out, "return %s", sizeConst(si))
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, "}")
+			continue
+		}
 		fmt.Fprintf(out, "// SizeBinary returns the size of the serialized object")
 		fmt.Fprintln(out)
 		fmt.Fprintf(out, "func (o *%s) SizeBinary() int {", si.Name)
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "size := 0")
 		fmt.Fprintln(out)
-		for _, sf := range si.Fields {
-			fmt.Fprintf(out, "// %s", sf.Name)
-			fmt.Fprintln(out)
-			switch sf.Type.Kind {
-			case "basic":
-				bt := basicTypes.Get(sf.Type.Name)
-				if bt == nil {
-					panic("unsupported basic type :" + sf.Type.Name)
-				}
-				fmt.Fprintf(out, "size += %d", bt.Size)
-				fmt.Fprintln(out)
-				if sf.Type.Name == "string" || sf.Type.Name == "[]byte" {
-					fmt.Fprintf(out, "size += len(o.%s)", sf.Name)
-				}
-			case "slice":
-				fmt.Fprintf(out, "size += %d", defaultLength)
-				fmt.Fprintln(out)
-				fmt.Fprintf(out, "for _, v := range o.%s {", sf.Name)
-				fmt.Fprintln(out)
-				fmt.Fprintln(out, "_ = v")
+		for i := 0; i < len(si.Fields); i++ {
+			if n := packedRun(si, i); n > 0 {
+				fmt.Fprintf(out, "// %s", fieldNames(si.Fields[i:i+n]))
 				fmt.Fprintln(out)
-				sizeField(out, sf.Type.ElemType, "v")
-				fmt.Fprintln(out, "}")
-			case "array":
-			case "struct":
-				sizeField(out, sf.Type, "o."+sf.Name)
-			case "map":
-				fmt.Fprintf(out, "size += %d", defaultLength) //map size
-				fmt.Fprintln(out)
-				fmt.Fprintf(out, "for k, v := range o.%s {", sf.Name)
-				fmt.Fprintln(out)
-				fmt.Fprintln(out, "_, _ = k, v")
-				sizeMapKey(out, sf.Type.KeyType, "k")
+				sizePacked(out, si.Fields[i:i+n])
+				i += n - 1
+				continue
+			}
+			if n := bitsRun(si, i); n > 0 {
+				fmt.Fprintf(out, "// %s", fieldNames(si.Fields[i:i+n]))
 				fmt.Fprintln(out)
-				sizeField(out, sf.Type.ElemType, "v")
-				fmt.Fprintln(out, "}")
-			default:
-				panic("unsupported type :" + sf.Type.Kind)
+				sizeBits(out, si.Fields[i:i+n])
+				i += n - 1
+				continue
 			}
+			sf := si.Fields[i]
+			fmt.Fprintf(out, "// %s", sf.Name)
 			fmt.Fprintln(out)
+			sizeField(out, si, sf.Type, "o."+sf.Name)
 		}
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "return size")
@@ -294,14 +623,14 @@ func (g *Generator) GenerateSize() ([]byte, error) {
 	return out.Bytes(), nil
 }
 
-func marshalMapKey(out io.Writer, ft *FieldType, name string) {
+func marshalMapKey(out io.Writer, si *StructInfo, ft *FieldType, name string) {
 	switch ft.Kind {
 	case "basic":
 		bt := basicTypes.Get(ft.Name)
 		if bt == nil {
 			panic("unsupported basic type :" + ft.Name)
 		}
-		fmt.Fprintf(out, "if n, err = o.Marshal%s(k, data[offset:]); err != nil {", bt.Type)
+		fmt.Fprintf(out, "if n, err = o.Marshal%s(%s, data[offset:]); err != nil {", bt.Type, encodeValue(si, bt, "k"))
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "return 0, err")
 		fmt.Fprintln(out, "}")
@@ -312,39 +641,222 @@ func marshalMapKey(out io.Writer, ft *FieldType, name string) {
 	}
 }
 
-func marshalField(out io.Writer, ft *FieldType, name string) {
+func marshalField(out io.Writer, si *StructInfo, ft *FieldType, name string) {
 	switch ft.Kind {
 	case "basic":
 		bt := basicTypes.Get(ft.Name)
 		if bt == nil {
 			panic("unsupported basic type :" + ft.Name)
 		}
-		fmt.Fprintf(out, "if n, err = o.Marshal%s(%s, data[offset:]); err != nil {", bt.Type, name)
+		fmt.Fprintf(out, "if n, err = o.Marshal%s(%s, data[offset:]); err != nil {", bt.Type, encodeValue(si, bt, name))
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "return 0, err")
 		fmt.Fprintln(out, "}")
 		fmt.Fprintf(out, "offset += n")
 		fmt.Fprintln(out)
 	case "slice":
+		if fn := collectionFn(si, ft.ElemType); fn != "" && bulkType(si, ft) == nil {
+			fmt.Fprintf(out, "if n, err = gobin.MarshalSlice(o.MarshalInt, o.Marshal%s, %s, data[offset:]); err != nil {", fn, name)
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, "return 0, err")
+			fmt.Fprintln(out, "}")
+			fmt.Fprintln(out, "offset += n")
+			break
+		}
 		fmt.Fprintf(out, "if n, err = o.MarshalInt(len(%s), data[offset:]); err != nil { // length", name)
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "return 0, err")
 		fmt.Fprintln(out, "}")
 		fmt.Fprintln(out, "offset += n")
+		fallthrough
+	case "array":
+		if bt := bulkType(si, ft); bt != nil {
+			fmt.Fprintf(out, "if n, err = o.Marshal%ss(%s, data[offset:]); err != nil {", bt.Type, bulkValue(ft, name))
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, "return 0, err")
+			fmt.Fprintln(out, "}")
+			fmt.Fprintln(out, "offset += n")
+			break
+		}
 		fmt.Fprintf(out, "for _, v := range %s {", name)
 		fmt.Fprintln(out)
-		marshalField(out, ft.ElemType, "v")
+		marshalField(out, si, ft.ElemType, "v")
+		fmt.Fprintln(out, "}")
+	case "pointer":
+		fmt.Fprintf(out, "if n, err = o.MarshalBool(%s == nil, data[offset:]); err != nil { // isnil", name)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "return 0, err")
+		fmt.Fprintln(out, "}")
+		fmt.Fprintln(out, "offset += n")
+		fmt.Fprintf(out, "if %s != nil {", name)
+		fmt.Fprintln(out)
+		marshalField(out, si, ft.ElemType, deref(name))
+		fmt.Fprintln(out, "}")
+	case "struct":
+		for _, sf := range ft.Fields {
+			marshalField(out, si, sf.Type, name+"."+sf.Name)
+		}
+	case "map":
+		if k, v := mapFns(si, ft); k != "" {
+			fmt.Fprintf(out, "if n, err = gobin.MarshalMap(o.MarshalInt, o.Marshal%s, o.Marshal%s, %s, data[offset:]); err != nil {", k, v, name)
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, "return 0, err")
+			fmt.Fprintln(out, "}")
+			fmt.Fprintln(out, "offset += n")
+			break
+		}
+		fmt.Fprintf(out, "if n, err = o.MarshalInt(len(%s), data[offset:]); err != nil { // length", name)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "return 0, err")
+		fmt.Fprintln(out, "}")
+		fmt.Fprintln(out, "offset += n")
+		rangeMap(out, si, ft, name)
+		marshalMapKey(out, si, ft.KeyType, "k")
+		marshalField(out, si, ft.ElemType, "v")
+		fmt.Fprintln(out, "}")
+	default:
+		panic("unsupported type :" + ft.Kind)
+	}
+}
+
+func appendField(out io.Writer, si *StructInfo, ft *FieldType, name string) {
+	switch ft.Kind {
+	case "basic":
+		bt := basicTypes.Get(ft.Name)
+		if bt == nil {
+			panic("unsupported basic type :" + ft.Name)
+		}
+		fmt.Fprintf(out, "dst = o.Append%s(dst, %s)", bt.Type, encodeValue(si, bt, name))
+		fmt.Fprintln(out)
+	case "slice":
+		fmt.Fprintf(out, "dst = o.AppendInt(dst, len(%s)) // length", name)
+		fmt.Fprintln(out)
+		fallthrough
+	case "array":
+		if bt := bulkType(si, ft); bt != nil {
+			fmt.Fprintf(out, "dst = o.Append%ss(dst, %s)", bt.Type, bulkValue(ft, name))
+			fmt.Fprintln(out)
+			break
+		}
+		fmt.Fprintf(out, "for _, v%d := range %s {", ft.Level, name)
+		fmt.Fprintln(out)
+		appendField(out, si, ft.ElemType, fmt.Sprintf("v%d", ft.Level))
+		fmt.Fprintln(out, "}")
+	case "pointer":
+		fmt.Fprintf(out, "dst = o.AppendBool(dst, %s == nil) // isnil", name)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "if %s != nil {", name)
+		fmt.Fprintln(out)
+		appendField(out, si, ft.ElemType, deref(name))
+		fmt.Fprintln(out, "}")
+	case "struct":
+		for _, sf := range ft.Fields {
+			appendField(out, si, sf.Type, name+"."+sf.Name)
+		}
+	case "map":
+		fmt.Fprintf(out, "dst = o.AppendInt(dst, len(%s)) // length", name)
+		fmt.Fprintln(out)
+		rangeMap(out, si, ft, name)
+		appendField(out, si, ft.KeyType, "k")
+		appendField(out, si, ft.ElemType, "v")
 		fmt.Fprintln(out, "}")
+	default:
+		panic("unsupported type :" + ft.Kind)
+	}
+}
+
+// writeValue returns the name of the Writer method writing a basic value of
+// bt. Writer writes a byte with WriteUint8, as Reader reads it.
+func writeValue(bt *baseType) string {
+	if bt.Type == "Byte" {
+		return "WriteUint8"
+	}
+	return "Write" + bt.Type
+}
+
+// writeField writes a field with a Writer named w.
+func writeField(out io.Writer, si *StructInfo, ft *FieldType, name string) {
+	switch ft.Kind {
+	case "basic":
+		bt := basicTypes.Get(ft.Name)
+		if bt == nil {
+			panic("unsupported basic type :" + ft.Name)
+		}
+		fmt.Fprintf(out, "w.%s(%s)", writeValue(bt), encodeValue(si, bt, name))
+		fmt.Fprintln(out)
+	case "slice":
+		fmt.Fprintf(out, "w.WriteLen(len(%s))", name)
+		fmt.Fprintln(out)
+		fallthrough
 	case "array":
+		fmt.Fprintf(out, "for _, v%d := range %s {", ft.Level, name)
+		fmt.Fprintln(out)
+		writeField(out, si, ft.ElemType, fmt.Sprintf("v%d", ft.Level))
+		fmt.Fprintln(out, "}")
+	case "pointer":
+		fmt.Fprintf(out, "w.WriteBool(%s == nil) // isnil", name)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "if %s != nil {", name)
+		fmt.Fprintln(out)
+		writeField(out, si, ft.ElemType, deref(name))
+		fmt.Fprintln(out, "}")
 	case "struct":
 		for _, sf := range ft.Fields {
-			marshalField(out, sf.Type, name+"."+sf.Name)
+			writeField(out, si, sf.Type, name+"."+sf.Name)
 		}
 	case "map":
+		fmt.Fprintf(out, "w.WriteLen(len(%s))", name)
+		fmt.Fprintln(out)
+		rangeMap(out, si, ft, name)
+		writeField(out, si, ft.KeyType, "k")
+		writeField(out, si, ft.ElemType, "v")
+		fmt.Fprintln(out, "}")
 	default:
 		panic("unsupported type :" + ft.Kind)
 	}
 }
+
+// generateMarshalWriter writes MarshalSegmented and MarshalWriter, encoding
+// the fields with a Writer so that large byte slices are referenced by a
+// SegmentedBuffer rather than copied.
+func generateMarshalWriter(out io.Writer, si *StructInfo) {
+	fmt.Fprintln(out, "// MarshalSegmented appends the encoding of o to seg, which references")
+	fmt.Fprintln(out, "// rather than copies byte slices of at least gobin.SegmentRefThreshold bytes.")
+	fmt.Fprintf(out, "func (o *%s) MarshalSegmented(seg *gobin.SegmentedBuffer) error {", si.Name)
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "w := gobin.NewSegmentedWriter(%s, seg)", readerCodec(si))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "o.MarshalWriter(w)")
+	fmt.Fprintln(out, "return w.Err()")
+	fmt.Fprintln(out, "}")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "// MarshalWriter encodes o with w, which must use the codec of o. Errors are")
+	fmt.Fprintln(out, "// recorded by w.")
+	fmt.Fprintf(out, "func (o *%s) MarshalWriter(w *gobin.Writer) {", si.Name)
+	fmt.Fprintln(out)
+	for i := 0; i < len(si.Fields); i++ {
+		if n := packedRun(si, i); n > 0 {
+			fmt.Fprintf(out, "// %s", fieldNames(si.Fields[i:i+n]))
+			fmt.Fprintln(out)
+			writePacked(out, si.Fields[i:i+n])
+			i += n - 1
+			continue
+		}
+		if n := bitsRun(si, i); n > 0 {
+			fmt.Fprintf(out, "// %s", fieldNames(si.Fields[i:i+n]))
+			fmt.Fprintln(out)
+			writeBitsField(out, si.Fields[i:i+n])
+			i += n - 1
+			continue
+		}
+		sf := si.Fields[i]
+		fmt.Fprintf(out, "// %s", sf.Name)
+		fmt.Fprintln(out)
+		writeField(out, si, sf.Type, "o."+sf.Name)
+	}
+	fmt.Fprintln(out, "}")
+}
+
 func (g *Generator) GenerateMarshal() ([]byte, error) {
 	var out = &bytes.Buffer{}
 
@@ -367,84 +879,105 @@ func (g *Generator) GenerateMarshal() ([]byte, error) {
 		fmt.Fprintln(out, "return data, nil")
 		fmt.Fprintln(out, "}")
 
-		fmt.Fprintf(out, "// MarshalTo encodes o as conform encoding.BinaryMarshaler.")
-		fmt.Fprintln(out)
-		fmt.Fprintf(out, "func (o *%s) MarshalTo(data []byte) (int, error) {", si.Name)
+		if straightLine(si) {
+			generateStraightMarshal(out, si)
+		} else {
+			generateMarshalTo(out, si)
+		}
+
+		fmt.Fprintf(out, "// AppendBinary appends the encoding of o to data as conform encoding.BinaryAppender.")
 		fmt.Fprintln(out)
-		fmt.Fprintln(out, "var (")
-		fmt.Fprintln(out, "offset, n int")
-		fmt.Fprintln(out, "err error")
-		fmt.Fprintln(out, ")")
+		fmt.Fprintf(out, "func (o *%s) AppendBinary(data []byte) ([]byte, error) {", si.Name)
 		fmt.Fprintln(out)
-		for _, sf := range si.Fields {
-			fmt.Fprintf(out, "// %s", sf.Name)
+		fmt.Fprintln(out, "return o.MarshalAppend(data)")
+		fmt.Fprintln(out, "}")
+
+		generateMarshalWriter(out, si)
+	}
+	return out.Bytes(), nil
+}
+
+// generateMarshalTo writes MarshalTo and MarshalAppend, encoding the fields
+// one by one.
+func generateMarshalTo(out io.Writer, si *StructInfo) {
+	fmt.Fprintf(out, "// MarshalTo encodes o as conform encoding.BinaryMarshaler.")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "func (o *%s) MarshalTo(data []byte) (int, error) {", si.Name)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "var (")
+	fmt.Fprintln(out, "offset, n int")
+	fmt.Fprintln(out, "err error")
+	fmt.Fprintln(out, ")")
+	fmt.Fprintln(out)
+	for i := 0; i < len(si.Fields); i++ {
+		if n := packedRun(si, i); n > 0 {
+			fmt.Fprintf(out, "// %s", fieldNames(si.Fields[i:i+n]))
 			fmt.Fprintln(out)
-			switch sf.Type.Kind {
-			case "basic":
-				bt := basicTypes.Get(sf.Type.Name)
-				if bt == nil {
-					panic("unsupported basic type :" + sf.Type.Name)
-				}
+			marshalPacked(out, si.Fields[i:i+n])
+			fmt.Fprintln(out)
+			i += n - 1
+			continue
+		}
+		if n := bitsRun(si, i); n > 0 {
+			fmt.Fprintf(out, "// %s", fieldNames(si.Fields[i:i+n]))
+			fmt.Fprintln(out)
+			marshalBits(out, si.Fields[i:i+n])
+			fmt.Fprintln(out)
+			i += n - 1
+			continue
+		}
+		sf := si.Fields[i]
+		fmt.Fprintf(out, "// %s", sf.Name)
+		fmt.Fprintln(out)
+		marshalField(out, si, sf.Type, "o."+sf.Name)
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "return offset, nil")
+	fmt.Fprintln(out, "}")
 
-				fmt.Fprintf(out, "if n, err = o.Marshal%s(o.%s, data[offset:]); err != nil {", bt.Type, sf.Name)
-				fmt.Fprintln(out)
-				fmt.Fprintln(out, "return 0, err")
-				fmt.Fprintln(out, "}")
-				fmt.Fprintf(out, "offset += n")
-			case "slice":
-				fmt.Fprintf(out, "if n, err = o.MarshalInt(len(o.%s), data[offset:]); err != nil { // length", sf.Name)
-				fmt.Fprintln(out)
-				fmt.Fprintln(out, "return 0, err")
-				fmt.Fprintln(out, "}")
-				fmt.Fprintln(out, "offset += n")
-				fmt.Fprintf(out, "for _, v := range o.%s {", sf.Name)
-				fmt.Fprintln(out)
-				marshalField(out, sf.Type.ElemType, "v")
-				fmt.Fprintln(out, "}")
-			case "array":
-			case "struct":
-				marshalField(out, sf.Type, "o."+sf.Name)
-			case "map":
-				fmt.Fprintf(out, "if n, err = o.MarshalInt(len(o.%s), data[offset:]); err != nil { // length", sf.Name)
-				fmt.Fprintln(out)
-				fmt.Fprintln(out, "return 0, err")
-				fmt.Fprintln(out, "}")
-				fmt.Fprintln(out, "offset += n")
-				fmt.Fprintf(out, "for k, v := range o.%s {", sf.Name)
-				fmt.Fprintln(out)
-				marshalMapKey(out, sf.Type.KeyType, "k")
-				marshalField(out, sf.Type.ElemType, "v")
-				fmt.Fprintln(out, "}")
-			default:
-				panic("unsupported type :" + sf.Type.Kind)
-			}
+	fmt.Fprintf(out, "// MarshalAppend appends the encoding of o to dst.")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "func (o *%s) MarshalAppend(dst []byte) ([]byte, error) {", si.Name)
+	fmt.Fprintln(out)
+	for i := 0; i < len(si.Fields); i++ {
+		if n := packedRun(si, i); n > 0 {
+			fmt.Fprintf(out, "// %s", fieldNames(si.Fields[i:i+n]))
 			fmt.Fprintln(out)
+			appendPacked(out, si.Fields[i:i+n])
+			i += n - 1
+			continue
 		}
+		if n := bitsRun(si, i); n > 0 {
+			fmt.Fprintf(out, "// %s", fieldNames(si.Fields[i:i+n]))
+			fmt.Fprintln(out)
+			appendBits(out, si.Fields[i:i+n])
+			i += n - 1
+			continue
+		}
+		sf := si.Fields[i]
+		fmt.Fprintf(out, "// %s", sf.Name)
 		fmt.Fprintln(out)
-		fmt.Fprintln(out, "return offset, nil")
-		fmt.Fprintln(out, "}")
+		appendField(out, si, sf.Type, "o."+sf.Name)
 	}
-	return out.Bytes(), nil
+	fmt.Fprintln(out, "return dst, nil")
+	fmt.Fprintln(out, "}")
 }
 
-func unmarshalMapKey(out io.Writer, ft *FieldType, name string) string {
+func unmarshalMapKey(out io.Writer, ft *FieldType, name, path string) {
 	switch ft.Kind {
 	case "basic":
 		bt := basicTypes.Get(ft.Name)
 		if bt == nil {
 			panic("unsupported basic type :" + ft.Name)
 		}
-		fmt.Fprintf(out, "k0, i, err := o.Unmarshal%s(data[n:])", bt.Type)
+		fmt.Fprintf(out, "r.Field(%q)", path)
 		fmt.Fprintln(out)
-		fmt.Fprintln(out, "if err != nil {")
-		fmt.Fprintln(out, "return 0, err")
-		fmt.Fprintln(out, "}")
-		fmt.Fprintf(out, "n += i")
+		fmt.Fprintf(out, "%s := %s", name, decodeValue(ft, bt))
 		fmt.Fprintln(out)
 	default:
 		panic("unsupported type :" + ft.Kind)
 	}
-	return "k0"
 }
 
 func getTypeString(expr ast.Expr) string {
@@ -458,8 +991,17 @@ func buildTypeString(sb *strings.Builder, expr ast.Expr) {
 	case *ast.Ident:
 		sb.WriteString(x.Name)
 	case *ast.ArrayType:
-		sb.WriteString("[]")
+		sb.WriteString("[")
+		if lit, ok := x.Len.(*ast.BasicLit); ok {
+			sb.WriteString(lit.Value)
+		}
+		sb.WriteString("]")
 		buildTypeString(sb, x.Elt)
+	case *ast.MapType:
+		sb.WriteString("map[")
+		buildTypeString(sb, x.Key)
+		sb.WriteString("]")
+		buildTypeString(sb, x.Value)
 	case *ast.StructType:
 		sb.WriteString("struct {\n")
 		for _, field := range x.Fields.List {
@@ -486,70 +1028,97 @@ func buildTypeString(sb *strings.Builder, expr ast.Expr) {
 	}
 }
 
-func unmarshalField(out io.Writer, ft *FieldType, name string) {
+// unmarshalField writes the decoding of name. path is the field path reported
+// by gobin.DecodeError, with a %d for every enclosing slice, array or map.
+func unmarshalField(out io.Writer, si *StructInfo, ft *FieldType, name, path string) {
+	depth := strings.Count(path, "%d")
 	switch ft.Kind {
 	case "basic":
 		bt := basicTypes.Get(ft.Name)
 		if bt == nil {
 			panic("unsupported basic type :" + ft.Name)
 		}
-		fmt.Fprintf(out, "if %s, i, err = o.Unmarshal%s(data[n:]); err != nil {", name, bt.Type)
+		fmt.Fprintf(out, "r.Field(%q)", path)
 		fmt.Fprintln(out)
-		fmt.Fprintln(out, "return 0, err")
-		fmt.Fprintln(out, "}")
-		fmt.Fprintf(out, "n += i")
+		fmt.Fprintf(out, "%s = %s", name, decodeValue(ft, bt))
 		fmt.Fprintln(out)
 	case "slice":
-		fmt.Fprintf(out, "if l, i, err = o.UnmarshalInt(data[n:]); err != nil { // length")
+		fmt.Fprintf(out, "r.Field(%q)", path)
 		fmt.Fprintln(out)
-		fmt.Fprintln(out, "return 0, err")
-		fmt.Fprintln(out, "}")
-		fmt.Fprintln(out, "n += i")
+		fmt.Fprintln(out, "l = r.ReadLen()")
+		fmt.Fprintf(out, "%s = gobin.MakeSlice[%s](r, l)", name, getTypeString(ft.Expr))
 		fmt.Fprintln(out)
-
-		if ft.Level == 0 && !strings.HasPrefix(name, "o.") {
-			name = "o." + name
-		}
-		ftName := ft.Name
-		if ftName == "[]" {
-			ftName = getTypeString(ft.Expr)
+		fallthrough
+	case "array":
+		if bt := bulkType(si, ft); bt != nil {
+			fmt.Fprintf(out, "r.Field(%q)", path)
+			fmt.Fprintln(out)
+			fmt.Fprintf(out, "r.ReadFunc(func(bs []byte) (int, error) { return %s.Unmarshal%ss(%s, bs) })",
+				readerCodec(si), bt.Type, bulkValue(ft, name))
+			fmt.Fprintln(out)
+			break
 		}
-
-		fmt.Fprintf(out, "%s = make(%s, l)", name, ftName)
+		fmt.Fprintf(out, "for i%d := range %s {", depth, name)
 		fmt.Fprintln(out)
-		fmt.Fprintf(out, "for i%d := range %s {", ft.Level, name)
+		fmt.Fprintf(out, "r.Index(%d, i%d)", depth, depth)
 		fmt.Fprintln(out)
-		unmarshalField(out, ft.ElemType, name+"[i"+fmt.Sprintf("%d", ft.Level)+"]")
+		unmarshalField(out, si, ft.ElemType, fmt.Sprintf("%s[i%d]", name, depth), path+"[%d]")
 		fmt.Fprintln(out, "}")
 		fmt.Fprintln(out)
-	case "array":
+	case "pointer":
+		fmt.Fprintf(out, "r.Field(%q)", path)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "if r.ReadBool() { // isnil")
+		fmt.Fprintf(out, "%s = nil", name)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "} else {")
+		fmt.Fprintf(out, "%s = new(%s)", name, getTypeString(ft.ElemType.Expr))
+		fmt.Fprintln(out)
+		unmarshalField(out, si, ft.ElemType, deref(name), path)
+		fmt.Fprintln(out, "}")
 	case "struct":
 		for _, sf := range ft.Fields {
-			unmarshalField(out, sf.Type, name+"."+sf.Name)
+			unmarshalField(out, si, sf.Type, name+"."+sf.Name, path+"."+sf.Name)
 		}
 	case "map":
-		fmt.Fprintf(out, "if l, i, err = o.UnmarshalInt(data[n:]); err != nil { // length")
+		fmt.Fprintf(out, "r.Field(%q)", path)
 		fmt.Fprintln(out)
-		fmt.Fprintln(out, "return 0, err")
-		fmt.Fprintln(out, "}")
-		fmt.Fprintln(out, "n += i")
+		fmt.Fprintln(out, "l = r.ReadLen()")
+		fmt.Fprintf(out, "%s = gobin.MakeMap[%s](r, l)", name, getTypeString(ft.Expr))
 		fmt.Fprintln(out)
-		if ft.Level == 0 {
-			name = "o." + name
-		}
-
-		fmt.Fprintf(out, "%s = make(%s, l)", name, ft.Name)
+		// MakeMap returns nil past the MaxAlloc limit, stop on the error
+		fmt.Fprintf(out, "for i%d, n%d := 0, l; i%d < n%d && r.Err() == nil; i%d++ {", depth, depth, depth, depth, depth)
 		fmt.Fprintln(out)
-		fmt.Fprintf(out, "for k := 0;k < l; k++ {")
+		fmt.Fprintf(out, "r.Index(%d, i%d)", depth, depth)
 		fmt.Fprintln(out)
-		k := unmarshalMapKey(out, ft.KeyType, name+"[k]")
-		unmarshalField(out, ft.ElemType, name+"["+k+"]")
+		k, v := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+		unmarshalMapKey(out, ft.KeyType, k, path+"[%d]")
+		if ft.ElemType.Kind == "basic" {
+			unmarshalField(out, si, ft.ElemType, name+"["+k+"]", path+"[%d]")
+		} else {
+			// map elements are not addressable, decode into a copy
+			fmt.Fprintf(out, "var %s %s", v, getTypeString(ft.ElemType.Expr))
+			fmt.Fprintln(out)
+			unmarshalField(out, si, ft.ElemType, v, path+"[%d]")
+			fmt.Fprintf(out, "%s[%s] = %s", name, k, v)
+			fmt.Fprintln(out)
+		}
 		fmt.Fprintln(out, "}")
 	default:
 		panic("unsupported type :" + ft.Kind)
 	}
 }
 
+// readerCodec returns the expression passed to gobin.NewReader: the embedded
+// codec if known, otherwise o itself, which implements gobin.Unmarshaler
+// through whatever it embeds.
+func readerCodec(si *StructInfo) string {
+	if si.Codec == "" {
+		return "o"
+	}
+	return "o." + si.Codec
+}
+
 func (g *Generator) GenerateUnmarshal() ([]byte, error) {
 	var out = &bytes.Buffer{}
 
@@ -564,36 +1133,68 @@ func (g *Generator) GenerateUnmarshal() ([]byte, error) {
 		fmt.Fprintln(out, "}")
 		fmt.Fprintln(out)
 
+		// UnmarshalOptions
+		fmt.Fprintf(out, "// UnmarshalOptions decodes o from data, limited by opts rather than gobin.DefaultDecodeOptions.")
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "func (o *%s) UnmarshalOptions(data []byte, opts gobin.DecodeOptions) error {", si.Name)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "r := gobin.NewReaderOptions(%s, data, opts)", readerCodec(si))
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "o.UnmarshalReader(r)")
+		fmt.Fprintln(out, "return r.Err()")
+		fmt.Fprintln(out, "}")
+		fmt.Fprintln(out)
+
+		if straightLine(si) {
+			generateStraightUnmarshal(out, si)
+			continue
+		}
+
 		fmt.Fprintf(out, "// UnmarshalFrom decodes o as conform encoding.BinaryUnmarshaler.")
 		fmt.Fprintln(out)
 		fmt.Fprintf(out, "func (o *%s) UnmarshalFrom(data []byte) (int, error) {", si.Name)
 		fmt.Fprintln(out)
-		fmt.Fprintln(out, "var (")
-		fmt.Fprintln(out, "i, n, l int")
-		fmt.Fprintln(out, "err error")
-		fmt.Fprintln(out, ")")
+		fmt.Fprintf(out, "r := gobin.NewReader(%s, data)", readerCodec(si))
 		fmt.Fprintln(out)
-		for _, sf := range si.Fields {
+		fmt.Fprintln(out, "o.UnmarshalReader(r)")
+		fmt.Fprintln(out, "if err := r.Err(); err != nil {")
+		fmt.Fprintln(out, "return 0, err")
+		fmt.Fprintln(out, "}")
+		fmt.Fprintln(out, "return r.Offset(), nil")
+		fmt.Fprintln(out, "}")
+		fmt.Fprintln(out)
+
+		fmt.Fprintf(out, "// UnmarshalReader decodes o from r, as conform gobin.ReaderUnmarshaler.")
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "func (o *%s) UnmarshalReader(r *gobin.Reader) {", si.Name)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "var l int")
+		fmt.Fprintln(out)
+		for i := 0; i < len(si.Fields); i++ {
+			if n := packedRun(si, i); n > 0 {
+				fmt.Fprintf(out, "// %s", fieldNames(si.Fields[i:i+n]))
+				fmt.Fprintln(out)
+				unmarshalPacked(out, si.Fields[i:i+n])
+				fmt.Fprintln(out)
+				i += n - 1
+				continue
+			}
+			if n := bitsRun(si, i); n > 0 {
+				fmt.Fprintf(out, "// %s", fieldNames(si.Fields[i:i+n]))
+				fmt.Fprintln(out)
+				unmarshalBits(out, si.Fields[i:i+n])
+				fmt.Fprintln(out)
+				i += n - 1
+				continue
+			}
+			sf := si.Fields[i]
 			fmt.Fprintf(out, "// %s", sf.Name)
 			fmt.Fprintln(out)
-			switch sf.Type.Kind {
-			case "basic":
-				unmarshalField(out, sf.Type, "o."+sf.Name)
-			case "slice":
-				unmarshalField(out, sf.Type, sf.Name)
-			case "array":
-			case "struct":
-				unmarshalField(out, sf.Type, "o."+sf.Name)
-			case "map":
-				unmarshalField(out, sf.Type, sf.Name)
-			default:
-				panic("unsupported type :" + sf.Type.Kind)
-			}
+			unmarshalField(out, si, sf.Type, "o."+sf.Name, sf.Name)
 			fmt.Fprintln(out)
 		}
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "_ = l")
-		fmt.Fprintln(out, "return n, nil")
 		fmt.Fprintln(out, "}")
 
 	}
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
 	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -32,3 +37,74 @@ func TestGen(t *testing.T) {
 	}
 	fmt.Printf("%s", code)
 }
+
+func TestCheckBits(t *testing.T) {
+	for _, field := range []string{
+		"A uint8 `gobin:\"bits=9\"`",
+		"A string `gobin:\"bits=3\"`",
+		"A uint16 `gobin:\"bits=x\"`",
+		"A int32 `gobin:\"bits=-1\"`",
+		"A int32 `gobin:\"bits=0\"`",
+	} {
+		src := filepath.Join(t.TempDir(), "bits.go")
+		code := "package bits\n\nimport \"github.com/millken/gobin\"\n\n//gobin:binary\ntype T struct {\n\tgobin.Safe\n\t" + field + "\n}\n"
+		if err := os.WriteFile(src, []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		g := &Generator{GoFile: src, OutName: src + ".out", Types: []string{"T"}}
+		if err := g.Run(); err == nil || !strings.Contains(err.Error(), "T.A: invalid bit field width") {
+			t.Errorf("%s: got %v, want an invalid width", field, err)
+		}
+	}
+}
+
+func TestCheckEmpty(t *testing.T) {
+	for _, field := range []string{
+		"A []struct{}",
+		"A [][0]int",
+		"A map[struct{}]Empty",
+		"A []Empty",
+		"A struct{ B *[]Empty }",
+		"A map[string][]struct{ E Empty }",
+	} {
+		src := filepath.Join(t.TempDir(), "empty.go")
+		code := "package empty\n\nimport \"github.com/millken/gobin\"\n\ntype Empty struct{}\n\n//gobin:binary\ntype T struct {\n\tgobin.Safe\n\t" + field + "\n}\n"
+		if err := os.WriteFile(src, []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		g := &Generator{GoFile: src, OutName: src + ".out", Types: []string{"T"}}
+		if err := g.Run(); err == nil || !strings.Contains(err.Error(), "T.A: unsupported") {
+			t.Errorf("%s: got %v, want an unsupported type", field, err)
+		}
+	}
+}
+
+var update = flag.Bool("update", false, "regenerate the example fixtures")
+
+// TestExampleFixtures checks that the code generated into the example package
+// is up to date. Run it with -update to regenerate it.
+func TestExampleFixtures(t *testing.T) {
+	for _, name := range []string{"photo", "scores", "switches"} {
+		src := filepath.Join("..", "..", "example", name+".go")
+		out := filepath.Join("..", "..", "example", name+"_bin.go")
+		g := &Generator{GoFile: src, OutName: out, Types: []string{name}}
+		code, err := g.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		code = append([]byte(fmt.Sprintf(generatedHeader, name+".go")), code...)
+		if *update {
+			if err := os.WriteFile(out, code, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(code, want) {
+			t.Errorf("%s is out of date, run go test -run TestExampleFixtures -update", out)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxPacked mirrors gobin.MaxPacked, the most bools of a packed run.
+const maxPacked = 64
+
+// packedRun returns the number of consecutive bool fields of si from the i-th
+// one encoded as packed bools, or 0 if si is not packed or the i-th field is
+// not a bool. Runs are cut at maxPacked bools.
+func packedRun(si *StructInfo, i int) int {
+	if !si.Packed {
+		return 0
+	}
+	n := 0
+	for i+n < len(si.Fields) && n < maxPacked && isBool(si.Fields[i+n].Type) {
+		n++
+	}
+	return n
+}
+
+// isBool reports whether ft is a bool, or a named type of one.
+func isBool(ft *FieldType) bool {
+	return ft.Kind == "basic" && ft.Name == "bool"
+}
+
+// namedBool returns the name of the named type of a bool field, such as Flag
+// of type Flag bool, or "" for a plain bool.
+func namedBool(sf StructField) string {
+	if sf.Type.Expr == nil {
+		return ""
+	}
+	if name := getTypeString(sf.Type.Expr); name != "bool" {
+		return name
+	}
+	return ""
+}
+
+// fieldNames returns the names of the fields of a run, for comments.
+func fieldNames(fields []StructField) string {
+	names := make([]string, len(fields))
+	for i, sf := range fields {
+		names[i] = sf.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func sizePacked(out io.Writer, fields []StructField) {
+	fmt.Fprintf(out, "size += %d", (len(fields)+7)/8)
+	fmt.Fprintln(out)
+}
+
+// packBools returns the expression packing fields into a uint64.
+func packBools(fields []StructField) string {
+	names := make([]string, len(fields))
+	for i, sf := range fields {
+		names[i] = "o." + sf.Name
+		if namedBool(sf) != "" {
+			names[i] = "bool(o." + sf.Name + ")"
+		}
+	}
+	return fmt.Sprintf("gobin.PackBools(%s)", strings.Join(names, ", "))
+}
+
+func marshalPacked(out io.Writer, fields []StructField) {
+	fmt.Fprintf(out, "if n, err = gobin.MarshalPacked(%s, %d, data[offset:]); err != nil {", packBools(fields), len(fields))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "return 0, err")
+	fmt.Fprintln(out, "}")
+	fmt.Fprintln(out, "offset += n")
+}
+
+func appendPacked(out io.Writer, fields []StructField) {
+	fmt.Fprintf(out, "dst = gobin.AppendPacked(dst, %s, %d)", packBools(fields), len(fields))
+	fmt.Fprintln(out)
+}
+
+func writePacked(out io.Writer, fields []StructField) {
+	fmt.Fprintf(out, "w.WritePacked(%s, %d)", packBools(fields), len(fields))
+	fmt.Fprintln(out)
+}
+
+// unmarshalPacked reads a packed run, reporting errors at the first field of
+// the run.
+func unmarshalPacked(out io.Writer, fields []StructField) {
+	fmt.Fprintf(out, "r.Field(%q)", fields[0].Name)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "{")
+	fmt.Fprintf(out, "bits := r.ReadPacked(%d)", len(fields))
+	fmt.Fprintln(out)
+	for i, sf := range fields {
+		if t := namedBool(sf); t != "" {
+			fmt.Fprintf(out, "o.%s = %s(bits&(1<<%d) != 0)", sf.Name, t, i)
+		} else {
+			fmt.Fprintf(out, "o.%s = bits&(1<<%d) != 0", sf.Name, i)
+		}
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintln(out, "}")
+}
@@ -47,11 +47,21 @@ type FieldType struct {
 type StructField struct {
 	Name string
 	Type *FieldType
+	// Bits is the width of a bit field, tagged gobin:"bits=N", or 0.
+	Bits int
 }
 
 type StructInfo struct {
 	Name   string
+	Codec  string // embedded gobin codec, e.g. Safe, Unsafe or Varint
 	Fields []StructField
+	// Canonical types sort map entries by encoded key and normalise floats
+	// and empty byte slices, see gobin.SortedKeys.
+	Canonical bool
+	// Packed types encode runs of consecutive bool fields as bit flags, see
+	// gobin.PackBools. It is set by a gobin:"packed" tag on the embedded
+	// codec.
+	Packed bool
 }
 
 func ParseFiles(paths []string) ([]*StructInfo, error) {
@@ -102,6 +112,12 @@ func parseAstStructs(node *ast.File) ([]*StructInfo, error) {
 			}
 
 			for _, field := range structType.Fields.List {
+				if len(field.Names) == 0 {
+					if codec := embeddedCodec(field.Type); codec != "" {
+						structInfo.Codec = codec
+					}
+					continue
+				}
 				for _, name := range field.Names {
 					structInfo.Fields = append(structInfo.Fields, StructField{
 						Name: name.Name,
@@ -121,9 +137,14 @@ func parseFieldType(expr ast.Expr, typeSpecs map[string]ast.Expr, level int) *Fi
 	switch t := expr.(type) {
 	case *ast.Ident:
 		if typeSpec, ok := typeSpecs[t.Name]; ok {
-			return parseFieldType(typeSpec, typeSpecs, level+1)
+			if isGUIDName(t.Name) && isByteArray16(typeSpec) {
+				return &FieldType{Name: "GUID", Kind: "basic", Level: level, Expr: t}
+			}
+			ft := parseFieldType(typeSpec, typeSpecs, level+1)
+			ft.Expr = t
+			return ft
 		}
-		return &FieldType{Name: t.Name, Kind: "basic", Level: level}
+		return &FieldType{Name: t.Name, Kind: "basic", Level: level, Expr: t}
 	case *ast.ArrayType:
 		if t.Len == nil {
 			if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
@@ -162,6 +183,15 @@ func parseFieldType(expr ast.Expr, typeSpecs map[string]ast.Expr, level int) *Fi
 			Level:    level,
 			Expr:     expr,
 		}
+	case *ast.SelectorExpr:
+		// a type of another package, only time.Time and time.Duration are
+		// known to the codecs
+		name := getTypeString(t)
+		if isGUIDName(t.Sel.Name) {
+			// e.g. gobin.GUID or uuid.UUID, assumed to be a [16]byte
+			name = "GUID"
+		}
+		return &FieldType{Name: name, Kind: "basic", Level: level, Expr: t}
 	case *ast.StructType:
 		fields := make([]StructField, 0, len(t.Fields.List))
 		for _, field := range t.Fields.List {
@@ -183,6 +213,25 @@ func parseFieldType(expr ast.Expr, typeSpecs map[string]ast.Expr, level int) *Fi
 	}
 }
 
+// isGUIDName reports whether a [16]byte type of that name is encoded as a
+// gobin.GUID, the rule gobin.Marshal applies.
+func isGUIDName(name string) bool {
+	return strings.HasSuffix(name, "GUID") || strings.HasSuffix(name, "UUID")
+}
+
+func isByteArray16(expr ast.Expr) bool {
+	at, ok := expr.(*ast.ArrayType)
+	if !ok {
+		return false
+	}
+	lit, ok := at.Len.(*ast.BasicLit)
+	if !ok || lit.Value != "16" {
+		return false
+	}
+	elt, ok := at.Elt.(*ast.Ident)
+	return ok && (elt.Name == "byte" || elt.Name == "uint8")
+}
+
 func printStructInfo(info *StructInfo, depth int) {
 	indent := strings.Repeat("  ", depth)
 	fmt.Printf("%sStruct: %s\n", indent, info.Name)
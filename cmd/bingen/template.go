@@ -465,6 +465,9 @@ var basicTypes = baseTypes{
 	{"uint16", 2, "Uint16"},
 	{"string", 8, "String"},
 	{"[]byte", 9, "Bytes"},
+	{"time.Time", 10, "Time"},
+	{"time.Duration", 8, "Duration"},
+	{"GUID", 16, "GUID"},
 }
 
 func (b baseTypes) Get(t string) *baseType {
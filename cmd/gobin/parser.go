@@ -75,14 +75,19 @@ func (p *Parser) Parse() error {
 		return err
 	}
 	options, consts, enums, structs := splitTopLevelDeclarations(parser.TopLevelDeclarations)
-	//parse package
-	if err := p.parsePackage(parser.Package.Identifier.String); err != nil {
-		return errors.New("parsePackage error: " + err.Error())
+	for _, s := range structs {
+		if err := checkBits(s); err != nil {
+			return err
+		}
 	}
-	//parse option
+	//parse option, selecting the codec the package imports depend on
 	if err := p.parseOption(options); err != nil {
 		return errors.New("parseOption error: " + err.Error())
 	}
+	//parse package
+	if err := p.parsePackage(parser.Package.Identifier.String, usesTime(structs), usesUnsafe(structs, codecName(p.option), isPacked(p.option))); err != nil {
+		return errors.New("parsePackage error: " + err.Error())
+	}
 	//parse const
 	if err := p.parseConst(consts); err != nil {
 		return errors.New("parseConst error: " + err.Error())
@@ -110,11 +115,35 @@ func (p *Parser) Parse() error {
 
 	return nil
 }
-func (p *Parser) parsePackage(name string) error {
-	err := prologTemplate.ExecuteTemplate(p.out, "prolog", name)
+func (p *Parser) parsePackage(name string, time, unsafe bool) error {
+	err := prologTemplate.ExecuteTemplate(p.out, "prolog", map[string]any{"Package": name, "Time": time, "Unsafe": unsafe})
 	return err
 }
 
+// usesTime reports whether a struct has a date or duration field, so that
+// the generated code imports time.
+func usesTime(structs []parser.Struct) bool {
+	for _, s := range structs {
+		for _, f := range s.Fields {
+			if t := f.Type.Type; t != nil && (*t == parser.Date || *t == parser.Duration) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// usesUnsafe reports whether a struct is encoded with straight-line stores,
+// so that the generated code imports unsafe.
+func usesUnsafe(structs []parser.Struct, codec string, packed bool) bool {
+	for _, s := range structs {
+		if structStraightLine(s.Fields, codec, packed) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Parser) parseStruct(structs []parser.Struct) error {
 	if len(structs) > 0 {
 		if err := structTemplate.ExecuteTemplate(p.out, "struct", map[string]any{"Structs": structs, "Options": p.option}); err != nil {
@@ -112,6 +112,25 @@ func TestSchema(t *testing.T) {
 	idx++
 }
 
+func TestGUID(t *testing.T) {
+	data, err := parser.ParseString(`
+  package example
+  struct device {
+	guid id
+	guid peers [repeated = true]
+  }
+	`)
+	assert.NoError(t, err)
+	stru := data.TopLevelDeclarations[0].(parser.Struct)
+	assert.Equal[string](t, "device", stru.Name.String)
+	assert.Equal[int](t, 2, len(stru.Fields))
+	assert.Equal[string](t, "id", stru.Fields[0].Name.String)
+	assert.Equal[parser.Type](t, parser.GUID, *stru.Fields[0].Type.Type)
+	assert.Equal[string](t, "gobin.GUID", stru.Fields[0].Type.Type.GoString())
+	assert.Equal[int](t, 16, stru.Fields[0].Type.Type.Size())
+	assert.Equal[parser.Type](t, parser.GUID, *stru.Fields[1].Type.Type)
+}
+
 func TestPebble(t *testing.T) {
 	input, err := os.ReadFile("../testdata/pebble.gobin")
 	assert.NoError(t, err)
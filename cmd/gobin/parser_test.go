@@ -272,3 +272,29 @@ func TestSimpleTemplate(t *testing.T) {
 	assert.NoError(t, p.Parse())
 	fmt.Println(out.String())
 }
+
+func TestBitsOption(t *testing.T) {
+	for _, field := range []string{
+		"uint8 a [bits = 9]",
+		"string a [bits = 3]",
+		"int16 a [bits = 0]",
+		"uint32 a [repeated = true, bits = 4]",
+	} {
+		src := "package example\nstruct t {\n" + field + "\n}\n"
+		p, err := NewParser(&bytes.Buffer{}, []byte(src))
+		assert.NoError(t, err)
+		err = p.Parse()
+		assert.Error(t, err, field)
+		assert.Contains(t, err.Error(), "invalid bit field width")
+	}
+}
+
+func TestRepeatedHelpers(t *testing.T) {
+	src := "package example\noption go_marshal = \"varint\"\nstruct t {\nint32 a [repeated = true]\n}\n"
+	out := &bytes.Buffer{}
+	p, err := NewParser(out, []byte(src), WithFormatted())
+	assert.NoError(t, err)
+	assert.NoError(t, p.Parse())
+	assert.Contains(t, out.String(), "gobin.SizeSlice(o.SizeInt, o.SizeInt32, o.A)")
+	assert.Contains(t, out.String(), "gobin.MarshalSlice(o.MarshalInt, o.MarshalInt32, o.A, data[offset:])")
+}
@@ -10,23 +10,28 @@ import (
 
 //nolint:gochecknoglobals
 var (
-	IntSize      = strconv.IntSize / 8
+	// IntSize is the wire size of int, uint and length prefixes, the same on
+	// every platform.
+	IntSize      = 8
 	typeToString = map[parser.Type]string{
-		parser.String: "String",
-		parser.Int:    "Int",
-		parser.Int8:   "Int8",
-		parser.Int16:  "Int16",
-		parser.Int32:  "Int32",
-		parser.Int64:  "Int64",
-		parser.Uint:   "Uint",
-		parser.Uint8:  "Uint8",
-		parser.Uint16: "Uint16",
-		parser.Uint32: "Uint32",
-		parser.Uint64: "Uint64",
-		parser.Float:  "Float32",
-		parser.Double: "Float64",
-		parser.Bool:   "Bool",
-		parser.Bytes:  "Bytes",
+		parser.String:   "String",
+		parser.Int:      "Int",
+		parser.Int8:     "Int8",
+		parser.Int16:    "Int16",
+		parser.Int32:    "Int32",
+		parser.Int64:    "Int64",
+		parser.Uint:     "Uint",
+		parser.Uint8:    "Uint8",
+		parser.Uint16:   "Uint16",
+		parser.Uint32:   "Uint32",
+		parser.Uint64:   "Uint64",
+		parser.Float:    "Float32",
+		parser.Double:   "Float64",
+		parser.Bool:     "Bool",
+		parser.Bytes:    "Bytes",
+		parser.Date:     "Time",
+		parser.Duration: "Duration",
+		parser.GUID:     "GUID",
 	}
 	funcMap = []template.FuncMap{map[string]interface{}{
 		"StructFieldGetOption": func(name string, fields []*parser.StructOption) *parser.Literal {
@@ -42,10 +47,68 @@ var (
 		"StructOptionIsBool": func(opt *parser.Literal) bool {
 			return isBool(opt)
 		},
-		"StructFieldLength": func(fields []parser.StructField) string {
-			var n int
+		"Codec": func(options map[string]parser.Literal) string {
+			return codecName(options)
+		},
+		"Packed": isPacked,
+		"FieldTag": func(f parser.StructField) string {
+			if n := fieldBits(f); n > 0 {
+				return fmt.Sprintf(" `gobin:\"bits=%d\"`", n)
+			}
+			return ""
+		},
+		"EmbeddedCodec": func(options map[string]parser.Literal) string {
+			if isPacked(options) {
+				return "gobin." + codecName(options) + " `gobin:\"packed\"`"
+			}
+			return "gobin." + codecName(options)
+		},
+		"StructSize":         structSize,
+		"StructStraightLine": structStraightLine,
+		"StructFieldStore": func(fields []parser.StructField) string {
 			var ret string
+			off := 0
 			for _, f := range fields {
+				ret += fmt.Sprintf(`*(*%s)(unsafe.Pointer(&data[%d])) = o.%s
+				`, f.Type.Type.GoString(), off, f.Name.String)
+				off += f.Type.Type.Size()
+			}
+			return ret
+		},
+		"StructFieldLoad": func(fields []parser.StructField) string {
+			var ret string
+			off := 0
+			for _, f := range fields {
+				if *f.Type.Type == parser.Bool {
+					ret += fmt.Sprintf(`if data[%d] > 1 {
+					return 0, &gobin.DecodeError{Offset: %d, Field: "%s", Type: "bool", Err: gobin.ErrInvalidBool}
+				}
+				`, off, off, f.Name.String)
+				}
+				ret += fmt.Sprintf(`o.%s = *(*%s)(unsafe.Pointer(&data[%d]))
+				`, f.Name.String, f.Type.Type.GoString(), off)
+				off += f.Type.Type.Size()
+			}
+			return ret
+		},
+		"StructFieldLength": func(fields []parser.StructField, codec string, packed bool) string {
+			if codec == "Varint" {
+				return structFieldVarintLength(fields, packed)
+			}
+			var n int
+			var ret string
+			for i := 0; i < len(fields); i++ {
+				if k := packedRun(fields, i, packed); k > 0 {
+					n += (k + 7) / 8
+					i += k - 1
+					continue
+				}
+				if k := bitsRun(fields, i); k > 0 {
+					n += bitsSize(fields[i : i+k])
+					i += k - 1
+					continue
+				}
+				f := fields[i]
 				opt := getOption("repeated", f.Options)
 				repeated := isBool(opt)
 				if f.Type.Type == nil {
@@ -79,27 +142,44 @@ var (
 					}
 
 				} else {
+					prefix := IntSize
+					if *f.Type.Type == parser.Bytes && codec != "Unsafe" {
+						// [isnil:bool][len:int]
+						prefix++
+					}
 					if repeated {
 						n += IntSize
 						ret += fmt.Sprintf(`
 						for _, v := range o.%s {
 							sz = sz + len(v) + %d
 						}
-						`, f.Name.String, IntSize)
+						`, f.Name.String, prefix)
 					} else {
 						ret += fmt.Sprintf(`
 					sz += len(o.%s)
 					`, f.Name.String)
-						n += IntSize
+						n += prefix
 					}
 				}
 			}
-			ret += fmt.Sprintf(`sz += %d`, n)
+			ret += fmt.Sprintf(`
+			sz += %d`, n)
 			return ret
 		},
-		"StructFieldMarshal": func(fields []parser.StructField) string {
+		"StructFieldMarshal": func(fields []parser.StructField, packed bool) string {
 			var ret string
-			for _, f := range fields {
+			for i := 0; i < len(fields); i++ {
+				if k := packedRun(fields, i, packed); k > 0 {
+					ret += marshalPacked(fields[i : i+k])
+					i += k - 1
+					continue
+				}
+				if k := bitsRun(fields, i); k > 0 {
+					ret += marshalBits(fields[i : i+k])
+					i += k - 1
+					continue
+				}
+				f := fields[i]
 				opt := getOption("repeated", f.Options)
 				repeated := isBool(opt)
 				if f.Type.Type == nil {
@@ -116,7 +196,7 @@ var (
 					}
 					`, f.Name.String, f.Name.String)
 					} else {
-						ret += fmt.Sprintf(`if n, err = o.%s.MarshalTo(data); err != nil {
+						ret += fmt.Sprintf(`if n, err = o.%s.MarshalTo(data[offset:]); err != nil {
 						return 0, err
 					}
 					offset += n
@@ -126,17 +206,11 @@ var (
 				}
 				if v, ok := typeToString[*f.Type.Type]; ok {
 					if repeated {
-						ret += fmt.Sprintf(`if n, err = o.MarshalInt(len(o.%s), data[offset:]); err != nil {
+						ret += fmt.Sprintf(`if n, err = gobin.MarshalSlice(o.MarshalInt, o.Marshal%s, o.%s, data[offset:]); err != nil {
 						return 0, err
-						}
-						offset += n
-						for _, v := range o.%s {
-						if n, err = o.Marshal%s(v, data[offset:]); err != nil {
-							return 0, err
-						}
-						offset += n
 					}
-						`, f.Name.String, f.Name.String, v)
+					offset += n
+		`, v, f.Name.String)
 					} else {
 						ret += fmt.Sprintf(`if n, err = o.Marshal%s(o.%s, data[offset:]); err != nil {
 						return 0, err
@@ -150,62 +224,157 @@ var (
 			}
 			return ret
 		},
-		"StructFieldUnmarshal": func(fields []parser.StructField) string {
+		"StructFieldAppend": func(fields []parser.StructField, packed bool) string {
 			var ret string
-			for _, f := range fields {
+			for i := 0; i < len(fields); i++ {
+				if k := packedRun(fields, i, packed); k > 0 {
+					ret += appendPacked(fields[i : i+k])
+					i += k - 1
+					continue
+				}
+				if k := bitsRun(fields, i); k > 0 {
+					ret += appendBits(fields[i : i+k])
+					i += k - 1
+					continue
+				}
+				f := fields[i]
 				opt := getOption("repeated", f.Options)
 				repeated := isBool(opt)
 				if f.Type.Type == nil {
 					if repeated {
-						ret += fmt.Sprintf(`if l, i, err = o.UnmarshalInt(data[n:]); err != nil {
-					return  0, err
+						ret += fmt.Sprintf(`dst = o.AppendInt(dst, len(o.%s))
+					for _, v := range o.%s {
+						if dst, err = v.MarshalAppend(dst); err != nil {
+							return nil, err
+						}
+					}
+					`, f.Name.String, f.Name.String)
+					} else {
+						ret += fmt.Sprintf(`if dst, err = o.%s.MarshalAppend(dst); err != nil {
+						return nil, err
+					}
+					`, f.Name.String)
+					}
+					continue
 				}
-				n += i
-				if l > 0 {
-				o.%s = make([]*%s, l)
-				for j := range o.%s {
-					o.%s[j] = new(%s)
-					if i, err = o.%s[j].UnmarshalTo(data[n:]); err != nil {
-						return 0, err
+				if v, ok := typeToString[*f.Type.Type]; ok {
+					if repeated {
+						ret += fmt.Sprintf(`dst = o.AppendInt(dst, len(o.%s))
+					for _, v := range o.%s {
+						dst = o.Append%s(dst, v)
 					}
-					n += i
+					`, f.Name.String, f.Name.String, v)
+					} else {
+						ret += fmt.Sprintf(`dst = o.Append%s(dst, o.%s)
+					`, v, f.Name.String)
+					}
+				} else {
+					panic("unknown type")
 				}
 			}
-				`, f.Name.String, UpperFirst(*f.Type.Reference), f.Name.String, f.Name.String, UpperFirst(*f.Type.Reference), f.Name.String)
+			return ret
+		},
+		"StructFieldWrite": func(fields []parser.StructField, packed bool) string {
+			var ret string
+			for i := 0; i < len(fields); i++ {
+				if k := packedRun(fields, i, packed); k > 0 {
+					ret += fmt.Sprintf(`w.WritePacked(%s, %d)
+					`, packBools(fields[i:i+k]), k)
+					i += k - 1
+					continue
+				}
+				if k := bitsRun(fields, i); k > 0 {
+					ret += writeBitsField(fields[i : i+k])
+					i += k - 1
+					continue
+				}
+				f := fields[i]
+				repeated := isBool(getOption("repeated", f.Options))
+				if f.Type.Type == nil {
+					if repeated {
+						ret += fmt.Sprintf(`w.WriteLen(len(o.%s))
+					for _, v := range o.%s {
+						v.MarshalWriter(w)
+					}
+					`, f.Name.String, f.Name.String)
 					} else {
-						ret += fmt.Sprintf(`if i, err = o.%s.UnmarshalTo(data[n:]); err != nil {
-					return 0, err
+						ret += fmt.Sprintf(`o.%s.MarshalWriter(w)
+					`, f.Name.String)
+					}
+					continue
 				}
-				n += i
-				`, f.Name.String)
+				v, ok := typeToString[*f.Type.Type]
+				if !ok {
+					panic("unknown type")
+				}
+				if repeated {
+					ret += fmt.Sprintf(`w.WriteLen(len(o.%s))
+					for _, v := range o.%s {
+						w.Write%s(v)
 					}
+					`, f.Name.String, f.Name.String, v)
+				} else {
+					ret += fmt.Sprintf(`w.Write%s(o.%s)
+					`, v, f.Name.String)
+				}
+			}
+			return strings.TrimSpace(ret)
+		},
+		"StructFieldUnmarshal": func(fields []parser.StructField, packed bool) string {
+			var ret string
+			for i := 0; i < len(fields); i++ {
+				if k := packedRun(fields, i, packed); k > 0 {
+					ret += unmarshalPacked(fields[i : i+k])
+					i += k - 1
 					continue
 				}
-				if v, ok := typeToString[*f.Type.Type]; ok {
-					if repeated {
-						ret += fmt.Sprintf(`if l, i, err = o.UnmarshalInt(data[n:]); err != nil {
-					return  0, err
+				if k := bitsRun(fields, i); k > 0 {
+					ret += unmarshalBits(fields[i : i+k])
+					i += k - 1
+					continue
 				}
-				n += i
-				if l > 0 {
-				o.%s = make([]%s, l)
+				f := fields[i]
+				opt := getOption("repeated", f.Options)
+				repeated := isBool(opt)
+				if f.Type.Type == nil {
+					if repeated {
+						ret += fmt.Sprintf(`r.Field("%s")
+			if l = r.ReadLen(); l > 0 {
+				o.%s = gobin.MakeSlice[[]*%s](r, l)
 				for j := range o.%s {
-					if v, m, err := o.Unmarshal%s(data[n:]); err != nil {
-						return  0, err
-					}else{
-						i = m
-						o.%s[j] = v
-					}
-					n += i
+					r.Index(0, j)
+					r.Field("%s[%%d]")
+					o.%s[j] = new(%s)
+					r.ReadValue(o.%s[j])
 				}
 			}
-				`, f.Name.String, f.Type.Type.GoString(), f.Name.String, v, f.Name.String)
+				`, f.Name.String, f.Name.String, UpperFirst(*f.Type.Reference), f.Name.String, f.Name.String, f.Name.String, UpperFirst(*f.Type.Reference), f.Name.String)
 					} else {
-						ret += fmt.Sprintf(`if o.%s, i, err = o.Unmarshal%s(data[n:]); err != nil {
-					return 0, err
+						ret += fmt.Sprintf(`r.Field("%s")
+			if o.%s == nil {
+				o.%s = new(%s)
+			}
+			r.ReadValue(o.%s)
+				`, f.Name.String, f.Name.String, f.Name.String, UpperFirst(*f.Type.Reference), f.Name.String)
+					}
+					continue
+				}
+				if v, ok := typeToString[*f.Type.Type]; ok {
+					if repeated {
+						ret += fmt.Sprintf(`r.Field("%s")
+			if l = r.ReadLen(); l > 0 {
+				o.%s = gobin.MakeSlice[[]%s](r, l)
+				r.Field("%s[%%d]")
+				for j := range o.%s {
+					r.Index(0, j)
+					o.%s[j] = r.Read%s()
 				}
-				n += i
-				`, f.Name.String, v)
+			}
+				`, f.Name.String, f.Name.String, f.Type.Type.GoString(), f.Name.String, f.Name.String, f.Name.String, v)
+					} else {
+						ret += fmt.Sprintf(`r.Field("%s")
+			o.%s = r.Read%s()
+				`, f.Name.String, f.Name.String, v)
 					}
 				} else {
 					panic("unknown type")
@@ -233,10 +402,16 @@ var (
 	}}
 
 	prologTemplate = template.Must(template.New("prolog").Parse(`
-package {{ . }}
+package {{ .Package }}
 
 import (
 	"fmt"
+	{{- if .Time }}
+	"time"
+	{{- end }}
+	{{- if .Unsafe }}
+	"unsafe"
+	{{- end }}
 	"github.com/millken/gobin"
 )
 `))
@@ -256,6 +431,321 @@ func getOption(name string, fields []*parser.StructOption) *parser.Literal {
 	return nil
 }
 
+// codecName returns the gobin codec selected by the go_marshal option.
+func codecName(options map[string]parser.Literal) string {
+	opt, ok := options["go_marshal"]
+	if !ok {
+		return "Safe"
+	}
+	switch GenerateLiteral(opt) {
+	case "unsafe":
+		return "Unsafe"
+	case "varint":
+		return "Varint"
+	case "bigendian":
+		return "BigEndian"
+	}
+	return "Safe"
+}
+
+// structSize returns the encoded size of a struct whose fields all have a
+// fixed width, or -1 if a field is repeated, a reference, a string or bytes,
+// or the codec is Varint. Packed bools take a bit each.
+func structSize(fields []parser.StructField, codec string, packed bool) int {
+	if codec == "Varint" {
+		return -1
+	}
+	n := 0
+	for i := 0; i < len(fields); i++ {
+		if k := packedRun(fields, i, packed); k > 0 {
+			n += (k + 7) / 8
+			i += k - 1
+			continue
+		}
+		if k := bitsRun(fields, i); k > 0 {
+			n += bitsSize(fields[i : i+k])
+			i += k - 1
+			continue
+		}
+		f := fields[i]
+		if f.Type.Type == nil || isBool(getOption("repeated", f.Options)) || f.Type.Type.Size() == 0 {
+			return -1
+		}
+		n += f.Type.Type.Size()
+	}
+	return n
+}
+
+// structStraightLine reports whether a struct is encoded with a single bounds
+// check and straight-line stores: a fixed size struct using the Unsafe codec
+// whose fields Unsafe encodes as their memory. int and uint are 32 bits wide
+// on some platforms, dates and GUIDs have encodings of their own, packed
+// structs pack their bools, and bit fields are not byte aligned.
+func structStraightLine(fields []parser.StructField, codec string, packed bool) bool {
+	if codec != "Unsafe" || packed || hasBits(fields) || structSize(fields, codec, packed) < 0 {
+		return false
+	}
+	for _, f := range fields {
+		switch *f.Type.Type {
+		case parser.Int, parser.Uint, parser.Date, parser.GUID:
+			return false
+		}
+	}
+	return true
+}
+
+// structFieldVarintLength sizes fields whose encoded width depends on the
+// value, asking the codec for the size of every value.
+func structFieldVarintLength(fields []parser.StructField, packed bool) string {
+	var ret string
+	for i := 0; i < len(fields); i++ {
+		if k := packedRun(fields, i, packed); k > 0 {
+			ret += fmt.Sprintf(`
+			sz += %d`, (k+7)/8)
+			i += k - 1
+			continue
+		}
+		if k := bitsRun(fields, i); k > 0 {
+			ret += fmt.Sprintf(`
+			sz += %d`, bitsSize(fields[i:i+k]))
+			i += k - 1
+			continue
+		}
+		f := fields[i]
+		repeated := isBool(getOption("repeated", f.Options))
+		if f.Type.Type == nil {
+			if repeated {
+				ret += fmt.Sprintf(`
+				sz += o.SizeInt(len(o.%s))
+				for _, v := range o.%s {
+					sz += v.Size()
+				}`, f.Name.String, f.Name.String)
+			} else {
+				ret += fmt.Sprintf(`
+				sz += o.%s.Size()`, f.Name.String)
+			}
+			continue
+		}
+		v, ok := typeToString[*f.Type.Type]
+		if !ok {
+			panic("unknown type")
+		}
+		if repeated {
+			ret += fmt.Sprintf(`
+			sz += gobin.SizeSlice(o.SizeInt, o.Size%s, o.%s)`, v, f.Name.String)
+		} else {
+			ret += fmt.Sprintf(`
+			sz += o.Size%s(o.%s)`, v, f.Name.String)
+		}
+	}
+	return ret
+}
+
+// maxPacked mirrors gobin.MaxPacked, the most bools of a packed run.
+const maxPacked = 64
+
+// isPacked reports whether the go_packed option packs runs of consecutive
+// bool fields as bit flags, see gobin.PackBools.
+func isPacked(options map[string]parser.Literal) bool {
+	opt, ok := options["go_packed"]
+	return ok && isBool(&opt)
+}
+
+// packedRun returns the number of consecutive bool fields from the i-th one
+// encoded as packed bools, or 0. Runs are cut at maxPacked bools.
+func packedRun(fields []parser.StructField, i int, packed bool) int {
+	if !packed {
+		return 0
+	}
+	n := 0
+	for i+n < len(fields) && n < maxPacked {
+		f := fields[i+n]
+		if f.Type.Type == nil || *f.Type.Type != parser.Bool || isBool(getOption("repeated", f.Options)) {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// packBools returns the expression packing a run of bool fields.
+func packBools(fields []parser.StructField) string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = "o." + f.Name.String
+	}
+	return fmt.Sprintf("gobin.PackBools(%s)", strings.Join(names, ", "))
+}
+
+func marshalPacked(fields []parser.StructField) string {
+	return fmt.Sprintf(`if n, err = gobin.MarshalPacked(%s, %d, data[offset:]); err != nil {
+		return 0, err
+	}
+	offset += n
+	`, packBools(fields), len(fields))
+}
+
+func appendPacked(fields []parser.StructField) string {
+	return fmt.Sprintf(`dst = gobin.AppendPacked(dst, %s, %d)
+	`, packBools(fields), len(fields))
+}
+
+// unmarshalPacked reads a run of packed bools, reporting errors at the first
+// field of the run.
+func unmarshalPacked(fields []parser.StructField) string {
+	ret := fmt.Sprintf(`r.Field("%s")
+	{
+		bits := r.ReadPacked(%d)
+	`, fields[0].Name.String, len(fields))
+	for i, f := range fields {
+		ret += fmt.Sprintf(`o.%s = bits&(1<<%d) != 0
+		`, f.Name.String, i)
+	}
+	return ret + `}
+	`
+}
+
+// fieldBits returns the width of a bit field declared with the bits option,
+// 0 if f has none, or -1 if it is not a positive number.
+func fieldBits(f parser.StructField) int {
+	opt := getOption("bits", f.Options)
+	if opt == nil {
+		return 0
+	}
+	n, err := strconv.Atoi(GenerateLiteral(*opt))
+	if err != nil || n < 1 {
+		return -1
+	}
+	return n
+}
+
+// bitsOf returns the widest bit field of type t, 0 if t is not an integer,
+// and whether it is signed. int and uint are 64 bits wide on the wire.
+func bitsOf(t parser.Type) (widest int, signed bool) {
+	switch t {
+	case parser.Int8, parser.Int16, parser.Int32, parser.Int64, parser.Int:
+		return 8 * t.Size(), true
+	case parser.Uint8, parser.Uint16, parser.Uint32, parser.Uint64, parser.Uint:
+		return 8 * t.Size(), false
+	}
+	return 0, false
+}
+
+// checkBits returns an error if a bit field of s is repeated, not an integer
+// or wider than its type.
+func checkBits(s parser.Struct) error {
+	for _, f := range s.Fields {
+		n := fieldBits(f)
+		if n == 0 {
+			continue
+		}
+		widest := 0
+		if f.Type.Type != nil && !isBool(getOption("repeated", f.Options)) {
+			widest, _ = bitsOf(*f.Type.Type)
+		}
+		if n < 1 || n > widest {
+			return fmt.Errorf("%s.%s: invalid bit field width %s", s.Name.String, f.Name.String, GenerateLiteral(*getOption("bits", f.Options)))
+		}
+	}
+	return nil
+}
+
+// bitsRun returns the number of consecutive bit fields from the i-th one, or
+// 0.
+func bitsRun(fields []parser.StructField, i int) int {
+	n := 0
+	for i+n < len(fields) && fieldBits(fields[i+n]) > 0 {
+		n++
+	}
+	return n
+}
+
+// hasBits reports whether a field is a bit field.
+func hasBits(fields []parser.StructField) bool {
+	for _, f := range fields {
+		if fieldBits(f) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// bitsSize returns the encoded size of a run of bit fields.
+func bitsSize(fields []parser.StructField) int {
+	n := 0
+	for _, f := range fields {
+		n += fieldBits(f)
+	}
+	return (n + 7) / 8
+}
+
+// writeBits writes a run of bit fields with a BitWriter named bw, failing
+// with ret, the values returned besides the error, on overflow.
+func writeBits(fields []parser.StructField, ret string) string {
+	var s string
+	for _, f := range fields {
+		call := fmt.Sprintf("bw.WriteBits(uint64(o.%s), %d)", f.Name.String, fieldBits(f))
+		if _, signed := bitsOf(*f.Type.Type); signed {
+			call = fmt.Sprintf("bw.WriteSignedBits(int64(o.%s), %d)", f.Name.String, fieldBits(f))
+		}
+		s += fmt.Sprintf(`if err = %s; err != nil {
+			return %s, fmt.Errorf("%s: %%w", err)
+		}
+		`, call, ret, f.Name.String)
+	}
+	return s
+}
+
+// marshalBits checks that the bytes of a run of bit fields fit, so that the
+// BitWriter appends in place.
+func marshalBits(fields []parser.StructField) string {
+	return fmt.Sprintf(`if len(data)-offset < %d {
+		return 0, gobin.ErrNotEnoughSpace
+	}
+	{
+		bw := gobin.NewBitWriter(data[offset:offset])
+		%sn = len(bw.Bytes())
+	}
+	offset += n
+	`, bitsSize(fields), writeBits(fields, "0"))
+}
+
+func appendBits(fields []parser.StructField) string {
+	return fmt.Sprintf(`{
+		bw := gobin.NewBitWriter(dst)
+		%sdst = bw.Bytes()
+	}
+	`, writeBits(fields, "nil"))
+}
+
+// writeBitsField writes a run of bit fields with a Writer named w.
+func writeBitsField(fields []parser.StructField) string {
+	return fmt.Sprintf(`w.WriteFunc(%d, func(data []byte) (int, error) {
+		var err error
+		bw := gobin.NewBitWriter(data[:0])
+		%sreturn len(bw.Bytes()), nil
+	})
+	`, bitsSize(fields), writeBits(fields, "0"))
+}
+
+// unmarshalBits reads a run of bit fields, reporting errors at the first
+// field of the run.
+func unmarshalBits(fields []parser.StructField) string {
+	ret := fmt.Sprintf(`r.Field("%s")
+	r.ReadBitFields(func(br *gobin.BitReader) {
+	`, fields[0].Name.String)
+	for _, f := range fields {
+		read := "ReadBits"
+		if _, signed := bitsOf(*f.Type.Type); signed {
+			read = "ReadSignedBits"
+		}
+		ret += fmt.Sprintf(`o.%s = %s(br.%s(%d))
+		`, f.Name.String, f.Type.Type.GoString(), read, fieldBits(f))
+	}
+	return ret + `})
+	`
+}
+
 func UpperFirst(s string) string {
 	return strings.ToUpper(s[:1]) + s[1:]
 }
@@ -314,15 +804,35 @@ func init() {
 		return 2, nil
 	}
 
+	// MarshalAppend appends the wire-format message to dst.
+	func (o *{{$parent.Name.String}}) MarshalAppend(dst []byte) ([]byte, error) {
+		return append(dst, byte(*o), byte(*o >> 8)), nil
+	}
+
+	// AppendBinary appends o to data as conform encoding.BinaryAppender.
+	func (o *{{$parent.Name.String}}) AppendBinary(data []byte) ([]byte, error) {
+		return o.MarshalAppend(data)
+	}
+
+	// MarshalWriter encodes o with w. Errors are recorded by w.
+	func (o *{{$parent.Name.String}}) MarshalWriter(w *gobin.Writer) {
+		w.WriteFunc(2, o.MarshalTo)
+	}
+
 	// UnmarshalTo reads a wire-format message from data.
 func (o *{{$parent.Name.String}}) UnmarshalTo(data []byte) (int, error) {
 	if len(data) < 2 {
-		return 0, fmt.Errorf("invalid data size %d", len(data))
+		return 0, &gobin.DecodeError{Type: "{{$parent.Name.String}}", Err: gobin.ErrNotEnoughSpace}
 	}
 	*o = {{$parent.Name.String}}(uint16(data[0]) | uint16(data[1])<<8)
 	return 2, nil
 }
 
+	// UnmarshalReader decodes o from r, as conform gobin.ReaderUnmarshaler.
+	func (o *{{$parent.Name.String}}) UnmarshalReader(r *gobin.Reader) {
+		r.ReadFunc(o.UnmarshalTo)
+	}
+
 	// MarshalBinary encodes o as conform encoding.BinaryMarshaler.
 	func (o *{{$parent.Name.String}}) MarshalBinary() ([]byte,error) {
 		data := make([]byte, 2)
@@ -344,7 +854,7 @@ func (o *{{$parent.Name.String}}) UnmarshalBinary(data []byte) error {
 {{ .Comments | FormatComment }}
 {{- end }}
 type {{.Name.String}} struct {
-	gobin.{{with $.Options.go_marshal}}{{if eq .Value "unsafe"}}Unsafe{{else}}Safe{{end}}{{else}}Safe{{end}}
+	{{EmbeddedCodec $.Options}}
 {{- range .Fields}}
 {{- if .Comments }}
 {{ .Comments | FormatComment }}
@@ -352,26 +862,85 @@ type {{.Name.String}} struct {
 {{- if .Type.Type | eq nil }}
 	{{.Name.String}}{{with .Options}}{{if . | StructFieldIsRepeat}}[]{{end}}{{end}}*{{GetString .Type.Reference}} 
 {{- else}}
-	{{.Name.String}} {{with .Options}}{{if . | StructFieldIsRepeat}}[]{{end}}{{end}}{{.Type.Type.GoString}}
+	{{.Name.String}} {{with .Options}}{{if . | StructFieldIsRepeat}}[]{{end}}{{end}}{{.Type.Type.GoString}}{{FieldTag .}}
 {{- end}}
 {{- end}}
 }
 
+{{- $size := StructSize .Fields (Codec $.Options) (Packed $.Options) }}
+{{- $straight := StructStraightLine .Fields (Codec $.Options) (Packed $.Options) }}
+{{- if ge $size 0 }}
+
+// {{.Name.String}}Size is the encoded size of {{.Name.String}}, which only has fixed width fields.
+const {{.Name.String}}Size = {{$size}}
+
+func (o *{{.Name.String}}) Size() int {
+	return {{.Name.String}}Size
+}
+{{- else }}
+
 func (o *{{.Name.String}}) Size() int {
 	var sz int
-	{{.Fields | StructFieldLength}}
+	{{StructFieldLength .Fields (Codec $.Options) (Packed $.Options)}}
 	return sz
 }
+{{- end }}
+{{- if $straight }}
+
+func (o *{{.Name.String}}) MarshalTo(data []byte) (int, error) {
+	if len(data) < {{.Name.String}}Size {
+		return 0, gobin.ErrNotEnoughSpace
+	}
+	{{.Fields | StructFieldStore}}
+	return {{.Name.String}}Size, nil
+}
+
+// MarshalAppend appends the wire-format message to dst.
+func (o *{{.Name.String}}) MarshalAppend(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, {{.Name.String}}Size)...)
+	_, err := o.MarshalTo(dst[n:])
+	return dst, err
+}
+{{- else }}
 
 func (o *{{.Name.String}}) MarshalTo(data []byte) (int, error) {
 	var (
 		offset, n int
 		err error
 	)
-	{{.Fields | StructFieldMarshal}}
+	{{StructFieldMarshal .Fields (Packed $.Options)}}
 	return offset, nil
 }
 
+// MarshalAppend appends the wire-format message to dst.
+func (o *{{.Name.String}}) MarshalAppend(dst []byte) ([]byte, error) {
+	var err error
+	{{StructFieldAppend .Fields (Packed $.Options)}}
+	_ = err
+	return dst, nil
+}
+{{- end }}
+
+// AppendBinary appends o to data as conform encoding.BinaryAppender.
+func (o *{{.Name.String}}) AppendBinary(data []byte) ([]byte, error) {
+	return o.MarshalAppend(data)
+}
+
+// MarshalSegmented appends the encoding of o to seg, which references
+// rather than copies byte slices of at least gobin.SegmentRefThreshold bytes.
+func (o *{{.Name.String}}) MarshalSegmented(seg *gobin.SegmentedBuffer) error {
+	w := gobin.NewSegmentedWriter(o.{{Codec $.Options}}, seg)
+	o.MarshalWriter(w)
+	return w.Err()
+}
+
+// MarshalWriter encodes o with w, which must use the codec of o. Errors are
+// recorded by w.
+func (o *{{.Name.String}}) MarshalWriter(w *gobin.Writer) {
+	{{StructFieldWrite .Fields (Packed $.Options)}}
+}
+
 // MarshalBinary encodes o as conform encoding.BinaryMarshaler.
 func (o *{{.Name.String}}) MarshalBinary() (data []byte, err error) {
 	sz := o.Size()
@@ -386,15 +955,38 @@ func (o *{{.Name.String}}) MarshalBinary() (data []byte, err error) {
 	return data, nil
 }
 
+{{- if $straight }}
+
 func (o *{{.Name.String}}) UnmarshalTo(data []byte) (int, error) {
-	var (
-		i, n, l int
-		err  error
-	)
-	{{.Fields | StructFieldUnmarshal}}
+	if len(data) < {{.Name.String}}Size {
+		return 0, &gobin.DecodeError{Type: "{{.Name.String}}", Err: gobin.ErrNotEnoughSpace}
+	}
+	{{.Fields | StructFieldLoad}}
+	return {{.Name.String}}Size, nil
+}
+
+// UnmarshalReader decodes o from r, as conform gobin.ReaderUnmarshaler.
+func (o *{{.Name.String}}) UnmarshalReader(r *gobin.Reader) {
+	r.ReadFunc(o.UnmarshalTo)
+}
+{{- else }}
+
+func (o *{{.Name.String}}) UnmarshalTo(data []byte) (int, error) {
+	r := gobin.NewReader(o.{{Codec $.Options}}, data)
+	o.UnmarshalReader(r)
+	if err := r.Err(); err != nil {
+		return 0, err
+	}
+	return r.Offset(), nil
+}
+
+// UnmarshalReader decodes o from r, as conform gobin.ReaderUnmarshaler.
+func (o *{{.Name.String}}) UnmarshalReader(r *gobin.Reader) {
+	var l int
+	{{StructFieldUnmarshal .Fields (Packed $.Options)}}
 	_ = l
-	return n, nil
 }
+{{- end }}
 
 // Unmarshal decodes data as conform encoding.BinaryUnmarshaler.
 func (o *{{.Name.String}}) UnmarshalBinary(data []byte) error {
@@ -402,6 +994,13 @@ func (o *{{.Name.String}}) UnmarshalBinary(data []byte) error {
 	return err
 
 }
+
+// UnmarshalOptions decodes o from data, limited by opts rather than gobin.DefaultDecodeOptions.
+func (o *{{.Name.String}}) UnmarshalOptions(data []byte, opts gobin.DecodeOptions) error {
+	r := gobin.NewReaderOptions(o.{{Codec $.Options}}, data, opts)
+	o.UnmarshalReader(r)
+	return r.Err()
+}
 {{- end}}
 `))
 }
@@ -0,0 +1,130 @@
+package gobin
+
+// The helpers below encode slices and maps with the functions of any codec,
+// the length prefix with its int function so that they match generated code
+// for types using the codec, e.g.
+// gobin.MarshalSlice(Varint{}.MarshalInt, Varint{}.MarshalInt32, v, bs).
+
+// MarshalSlice encodes v as [len:int][v[0]]...[v[len-1]], the length encoded
+// with lfn and every element with fn.
+func MarshalSlice[T any](lfn MarshallerFn[int], fn MarshallerFn[T], v []T, bs []byte) (n int, err error) {
+	if n, err = lfn(len(v), bs); err != nil {
+		return 0, err
+	}
+	for _, e := range v {
+		i, err := fn(e, bs[n:])
+		if err != nil {
+			return 0, err
+		}
+		n += i
+	}
+	return n, nil
+}
+
+// UnmarshalSlice decodes a slice encoded by MarshalSlice, the length decoded
+// with lfn and every element with fn. Since every element takes at least one byte, a length
+// greater than the remaining data returns ErrNotEnoughSpace before anything
+// is allocated.
+func UnmarshalSlice[T any](lfn UnmarshallerFn[int], fn UnmarshallerFn[T], bs []byte) (v []T, n int, err error) {
+	l, n, err := unmarshalLen(lfn, bs)
+	if err != nil {
+		return nil, 0, err
+	}
+	v = make([]T, l)
+	for i := range v {
+		e, m, err := fn(bs[n:])
+		if err != nil {
+			return nil, 0, err
+		}
+		v[i] = e
+		n += m
+	}
+	return v, n, nil
+}
+
+// SizeSlice returns the size of v encoded by MarshalSlice, lsize returning the
+// size of the length, e.g. Varint{}.SizeInt or FixedIntSize, and size that of
+// a single element.
+func SizeSlice[T any](lsize func(int) int, size func(T) int, v []T) int {
+	n := lsize(len(v))
+	for _, e := range v {
+		n += size(e)
+	}
+	return n
+}
+
+// MarshalMap encodes v as [len:int] followed by every key and value, the
+// length encoded with lfn, keys and values with kfn and vfn. Entries are
+// written in map iteration order.
+func MarshalMap[K comparable, V any](lfn MarshallerFn[int], kfn MarshallerFn[K], vfn MarshallerFn[V], v map[K]V, bs []byte) (n int, err error) {
+	if n, err = lfn(len(v), bs); err != nil {
+		return 0, err
+	}
+	for k, e := range v {
+		i, err := kfn(k, bs[n:])
+		if err != nil {
+			return 0, err
+		}
+		n += i
+		if i, err = vfn(e, bs[n:]); err != nil {
+			return 0, err
+		}
+		n += i
+	}
+	return n, nil
+}
+
+// UnmarshalMap decodes a map encoded by MarshalMap, with the same length
+// check as UnmarshalSlice.
+func UnmarshalMap[K comparable, V any](lfn UnmarshallerFn[int], kfn UnmarshallerFn[K], vfn UnmarshallerFn[V], bs []byte) (v map[K]V, n int, err error) {
+	l, n, err := unmarshalLen(lfn, bs)
+	if err != nil {
+		return nil, 0, err
+	}
+	v = make(map[K]V, l)
+	for i := 0; i < l; i++ {
+		k, m, err := kfn(bs[n:])
+		if err != nil {
+			return nil, 0, err
+		}
+		n += m
+		e, m, err := vfn(bs[n:])
+		if err != nil {
+			return nil, 0, err
+		}
+		n += m
+		v[k] = e
+	}
+	return v, n, nil
+}
+
+// SizeMap returns the size of v encoded by MarshalMap, lsize returning the
+// size of the length as for SizeSlice.
+func SizeMap[K comparable, V any](lsize func(int) int, ksize func(K) int, vsize func(V) int, v map[K]V) int {
+	n := lsize(len(v))
+	for k, e := range v {
+		n += ksize(k) + vsize(e)
+	}
+	return n
+}
+
+// FixedIntSize returns the size of an int encoded by Safe, Unsafe or
+// BigEndian, for SizeSlice and SizeMap.
+func FixedIntSize(int) int {
+	return 8
+}
+
+// unmarshalLen decodes the length prefix of a slice or map with lfn, checking
+// it against the remaining data.
+func unmarshalLen(lfn UnmarshallerFn[int], bs []byte) (l int, n int, err error) {
+	if l, n, err = lfn(bs); err != nil {
+		return 0, 0, err
+	}
+	if l < 0 {
+		return 0, 0, ErrNegativeLength
+	}
+	if l > len(bs)-n {
+		return 0, 0, ErrNotEnoughSpace
+	}
+	return l, n, nil
+}
@@ -0,0 +1,100 @@
+package gobin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollection(t *testing.T) {
+	r := require.New(t)
+	c := Safe{}
+	t.Run("slice", func(t *testing.T) {
+		v := []string{"a", "bc", ""}
+		sz := SizeSlice(FixedIntSize, func(s string) int { return 8 + len(s) }, v)
+		r.Equal(8+8*3+3, sz)
+		bs := make([]byte, sz)
+		n, err := MarshalSlice(c.MarshalInt, c.MarshalString, v, bs)
+		r.NoError(err)
+		r.Equal(sz, n)
+		want := c.AppendInt(nil, len(v))
+		for _, s := range v {
+			want = c.AppendString(want, s)
+		}
+		r.Equal(want, bs)
+		v2, n, err := UnmarshalSlice(c.UnmarshalInt, c.UnmarshalString, bs)
+		r.NoError(err)
+		r.Equal(sz, n)
+		r.Equal(v, v2)
+
+		_, err = MarshalSlice(c.MarshalInt, c.MarshalString, v, bs[:sz-1])
+		r.ErrorIs(err, ErrNotEnoughSpace)
+		_, _, err = UnmarshalSlice(c.UnmarshalInt, c.UnmarshalString, bs[:sz-1])
+		r.ErrorIs(err, ErrNotEnoughSpace)
+	})
+	t.Run("map", func(t *testing.T) {
+		v := map[uint8]int32{1: -1, 2: -2}
+		sz := SizeMap(FixedIntSize, func(uint8) int { return 1 }, func(int32) int { return 4 }, v)
+		r.Equal(8+2*5, sz)
+		bs := make([]byte, sz)
+		n, err := MarshalMap(c.MarshalInt, c.MarshalUint8, c.MarshalInt32, v, bs)
+		r.NoError(err)
+		r.Equal(sz, n)
+		v2, n, err := UnmarshalMap(c.UnmarshalInt, c.UnmarshalUint8, c.UnmarshalInt32, bs)
+		r.NoError(err)
+		r.Equal(sz, n)
+		r.Equal(v, v2)
+	})
+	t.Run("should encode the length with the codec of the elements", func(t *testing.T) {
+		v := []int32{1, -2, 300}
+		be := BigEndian{}
+		bs := make([]byte, SizeSlice(FixedIntSize, func(int32) int { return 4 }, v))
+		n, err := MarshalSlice(be.MarshalInt, be.MarshalInt32, v, bs)
+		r.NoError(err)
+		want := be.AppendInt(nil, len(v))
+		for _, e := range v {
+			want = be.AppendInt32(want, e)
+		}
+		r.Equal(want, bs[:n])
+		v2, _, err := UnmarshalSlice(be.UnmarshalInt, be.UnmarshalInt32, bs)
+		r.NoError(err)
+		r.Equal(v, v2)
+
+		vi := Varint{}
+		sz := SizeSlice(vi.SizeInt, vi.SizeInt32, v)
+		bs = make([]byte, sz)
+		n, err = MarshalSlice(vi.MarshalInt, vi.MarshalInt32, v, bs)
+		r.NoError(err)
+		r.Equal(sz, n)
+		want = vi.AppendInt(nil, len(v))
+		for _, e := range v {
+			want = vi.AppendInt32(want, e)
+		}
+		r.Equal(want, bs)
+		v2, _, err = UnmarshalSlice(vi.UnmarshalInt, vi.UnmarshalInt32, bs)
+		r.NoError(err)
+		r.Equal(v, v2)
+
+		m := map[string]uint16{"a": 1, "bc": 65535}
+		sz = SizeMap(vi.SizeInt, vi.SizeString, vi.SizeUint16, m)
+		bs = make([]byte, sz)
+		n, err = MarshalMap(vi.MarshalInt, vi.MarshalString, vi.MarshalUint16, m, bs)
+		r.NoError(err)
+		r.Equal(sz, n)
+		m2, n, err := UnmarshalMap(vi.UnmarshalInt, vi.UnmarshalString, vi.UnmarshalUint16, bs)
+		r.NoError(err)
+		r.Equal(sz, n)
+		r.Equal(m, m2)
+	})
+	t.Run("should check the length before allocating", func(t *testing.T) {
+		bs := make([]byte, 16)
+		_, err := c.MarshalInt(1<<40, bs)
+		r.NoError(err)
+		_, _, err = UnmarshalSlice(c.UnmarshalInt, c.UnmarshalUint64, bs)
+		r.ErrorIs(err, ErrNotEnoughSpace)
+		_, err = c.MarshalInt(-1, bs)
+		r.NoError(err)
+		_, _, err = UnmarshalMap(c.UnmarshalInt, c.UnmarshalUint8, c.UnmarshalUint8, bs)
+		r.ErrorIs(err, ErrNegativeLength)
+	})
+}
@@ -0,0 +1,74 @@
+package gobin
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"sync"
+)
+
+// DefaultCompressThreshold is a payload size below which compression rarely
+// pays off, for WriteCompressedFrame and Encoder.SetCompression.
+const DefaultCompressThreshold = 512
+
+// The compressors and decompressors of frames are pooled, their state being
+// large to allocate for every frame.
+var (
+	flateWriters = sync.Pool{
+		New: func() interface{} {
+			w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+			return w
+		},
+	}
+	flateReaders = sync.Pool{
+		New: func() interface{} { return flate.NewReader(nil) },
+	}
+)
+
+// compressFrame compresses the payload of frame in place and seals its
+// header, returning the size of the compressed frame, unless compression does
+// not make it smaller.
+func compressFrame(frame []byte) (int, bool) {
+	payload := frame[checksumFrameHeaderSize:]
+	cbuf := NewBufferFromPoolWithCap(len(frame))
+	defer cbuf.ReturnToPool()
+	cbuf.Bytes = append(cbuf.Bytes, frame[:checksumFrameHeaderSize]...)
+	w := flateWriters.Get().(*flate.Writer)
+	defer flateWriters.Put(w)
+	w.Reset(cbuf)
+	if _, err := w.Write(payload); err != nil {
+		return 0, false
+	}
+	if err := w.Close(); err != nil || len(cbuf.Bytes) >= len(frame) {
+		return 0, false
+	}
+	sealFrame(cbuf.Bytes, frameCompressed)
+	return copy(frame, cbuf.Bytes), true
+}
+
+// decompress appends the decompression of src to dst. It fails with
+// ErrFrameTooLarge if that takes more than max bytes.
+func decompress(dst, src []byte, max int) ([]byte, error) {
+	r := flateReaders.Get().(io.ReadCloser)
+	defer flateReaders.Put(r)
+	if err := r.(flate.Resetter).Reset(bytes.NewReader(src), nil); err != nil {
+		return nil, err
+	}
+	start := len(dst)
+	for {
+		if len(dst) == cap(dst) {
+			dst = append(dst, 0)[:len(dst)]
+		}
+		n, err := r.Read(dst[len(dst):cap(dst)])
+		dst = dst[:len(dst)+n]
+		if len(dst)-start > max {
+			return nil, ErrFrameTooLarge
+		}
+		if err == io.EOF {
+			return dst, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package gobin
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	r := require.New(t)
+	large := &streamValue{ID: 1, Name: strings.Repeat("GetTab ", 200)}
+	small := &streamValue{ID: 2, Name: "hello"}
+	t.Run("should compress frames from the threshold on", func(t *testing.T) {
+		var buf Buffer
+		r.NoError(WriteCompressedFrame(&buf, large, DefaultCompressThreshold))
+		r.Less(len(buf.Bytes), large.SizeBinary()/4)
+		l, _, err := unmarshalSafeInteger32[uint32](buf.Bytes)
+		r.NoError(err)
+		r.NotZero(l & frameCompressed)
+		start := len(buf.Bytes)
+		r.NoError(WriteCompressedFrame(&buf, small, DefaultCompressThreshold))
+		r.Equal(checksumFrameHeaderSize+small.SizeBinary(), len(buf.Bytes)-start)
+
+		var v streamValue
+		n, err := ReadFrame(buf.Bytes, &v)
+		r.NoError(err)
+		r.Equal(start, n)
+		r.Equal(*large, v)
+		_, err = ReadFrame(buf.Bytes[n:], &v)
+		r.NoError(err)
+		r.Equal(*small, v)
+	})
+	t.Run("should store incompressible payloads as they are", func(t *testing.T) {
+		name := make([]byte, 1024)
+		_, err := rand.Read(name)
+		r.NoError(err)
+		v := &streamValue{ID: 3, Name: string(name)}
+		var buf Buffer
+		r.NoError(WriteCompressedFrame(&buf, v, 1))
+		r.Equal(checksumFrameHeaderSize+v.SizeBinary(), len(buf.Bytes))
+	})
+	t.Run("should decompress streams transparently", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetCompression(DefaultCompressThreshold)
+		values := []*streamValue{large, small, large}
+		for _, v := range values {
+			r.NoError(enc.Encode(v))
+		}
+		r.Less(buf.Len(), large.SizeBinary())
+		dec := NewDecoder(bytes.NewReader(buf.Bytes()))
+		dec.SetChecksum(true)
+		defer dec.Release()
+		for _, want := range values {
+			var v streamValue
+			r.NoError(dec.Decode(&v))
+			r.Equal(*want, v)
+		}
+		r.ErrorIs(dec.Decode(&streamValue{}), io.EOF)
+
+		dec = NewDecoder(bytes.NewReader(buf.Bytes()))
+		dec.SetChecksum(true)
+		dec.SetMaxFrameSize(large.SizeBinary() - 1)
+		r.ErrorIs(dec.Decode(&streamValue{}), ErrFrameTooLarge)
+	})
+}
@@ -0,0 +1,114 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding"
+	"errors"
+
+	"github.com/millken/gobin"
+)
+
+var (
+	// ErrKeyID is returned by Decrypt when data is encrypted with another key.
+	ErrKeyID = errors.New("key id mismatch")
+	// ErrDecrypt is returned by Decrypt when data fails authentication: it
+	// was modified, or encrypted with another key or message type.
+	ErrDecrypt = errors.New("message authentication failed")
+)
+
+// Key is an AES-GCM key identified by an ID, written in the clear in front of
+// every message so that the key can be rotated.
+type Key struct {
+	ID   uint32
+	aead cipher.AEAD
+}
+
+// NewKey returns the key id for secret, which must be 16, 24 or 32 bytes
+// long for AES-128, AES-192 or AES-256.
+func NewKey(id uint32, secret []byte) (*Key, error) {
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{ID: id, aead: aead}, nil
+}
+
+// An encrypted message is encoded as
+//
+//	[version:uint8][key:uint32][nonce:bytes][ciphertext:bytes]
+//
+// with gobin.Safe. The nonce is random. The version, the key id and the
+// message type are authenticated as associated data; the type is not written,
+// the reader must know it.
+
+// Encrypt encodes v and encrypts it with key as a message of type typ.
+func Encrypt(key *Key, typ uint32, v encoding.BinaryMarshaler) ([]byte, error) {
+	body, err := v.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	c := gobin.Safe{}
+	data := c.AppendUint8(nil, Version)
+	data = c.AppendUint32(data, key.ID)
+	ad := c.AppendUint32(data[:len(data):len(data)], typ)
+	data = c.AppendBytes(data, nonce)
+	sealed := key.aead.Seal(nil, nonce, body, ad)
+	return c.AppendBytes(data, sealed), nil
+}
+
+// Decrypt decrypts data, encrypted by Encrypt with key as a message of type
+// typ, and decodes it into v. Nothing is decoded unless data authenticates
+// and no bytes follow the ciphertext.
+func Decrypt(key *Key, typ uint32, data []byte, v encoding.BinaryUnmarshaler) error {
+	r := gobin.NewReader(gobin.Safe{}, data)
+	r.Field("Version")
+	if version := r.ReadUint8(); r.Err() == nil && version != Version {
+		return ErrVersion
+	}
+	r.Field("Key")
+	if id := r.ReadUint32(); r.Err() == nil && id != key.ID {
+		return ErrKeyID
+	}
+	ad := gobin.Safe{}.AppendUint32(data[:r.Offset():r.Offset()], typ)
+	r.Field("Nonce")
+	nonce := r.ReadBytes()
+	r.Field("Ciphertext")
+	sealed := r.ReadBytes()
+	if err := r.Err(); err != nil {
+		return err
+	}
+	if r.Offset() != len(data) {
+		return ErrTrailingData
+	}
+	if len(nonce) != key.aead.NonceSize() {
+		return ErrDecrypt
+	}
+	body, err := key.aead.Open(nil, nonce, sealed, ad)
+	if err != nil {
+		return ErrDecrypt
+	}
+	return v.UnmarshalBinary(body)
+}
+
+// KeyID returns the id of the key data is encrypted with, e.g. to pick it
+// from the keys in rotation.
+func KeyID(data []byte) (uint32, error) {
+	r := gobin.NewReader(gobin.Safe{}, data)
+	r.Field("Version")
+	if version := r.ReadUint8(); r.Err() == nil && version != Version {
+		return 0, ErrVersion
+	}
+	r.Field("Key")
+	id := r.ReadUint32()
+	return id, r.Err()
+}
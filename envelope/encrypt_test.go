@@ -0,0 +1,56 @@
+package envelope
+
+import (
+	"testing"
+
+	"github.com/millken/gobin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncrypt(t *testing.T) {
+	r := require.New(t)
+	key, err := NewKey(3, []byte("0123456789abcdef0123456789abcdef"))
+	r.NoError(err)
+	v := &reading{Sensor: "s1", Values: map[string]float64{"lat": 52.1, "lon": 4.3}}
+
+	data, err := Encrypt(key, 7, v)
+	r.NoError(err)
+	r.NotContains(string(data), "s1")
+	id, err := KeyID(data)
+	r.NoError(err)
+	r.Equal(uint32(3), id)
+	var v2 reading
+	r.NoError(Decrypt(key, 7, data, &v2))
+	r.Equal(*v, v2)
+
+	data2, err := Encrypt(key, 7, v)
+	r.NoError(err)
+	r.NotEqual(data, data2, "nonces should differ")
+
+	t.Run("should fail authentication", func(t *testing.T) {
+		for i := 5; i < len(data); i++ {
+			bad := append([]byte(nil), data...)
+			bad[i] ^= 1
+			var v3 reading
+			r.Error(Decrypt(key, 7, bad, &v3), "byte %d", i)
+			r.Zero(v3)
+		}
+		r.ErrorIs(Decrypt(key, 8, data, &reading{}), ErrDecrypt)
+		other, err := NewKey(3, []byte("fedcba9876543210"))
+		r.NoError(err)
+		r.ErrorIs(Decrypt(other, 7, data, &reading{}), ErrDecrypt)
+	})
+	t.Run("should check the header", func(t *testing.T) {
+		other, err := NewKey(4, []byte("0123456789abcdef0123456789abcdef"))
+		r.NoError(err)
+		r.ErrorIs(Decrypt(other, 7, data, &reading{}), ErrKeyID)
+		bad := append([]byte{Version + 1}, data[1:]...)
+		r.ErrorIs(Decrypt(key, 7, bad, &reading{}), ErrVersion)
+		_, err = KeyID(bad)
+		r.ErrorIs(err, ErrVersion)
+		r.ErrorIs(Decrypt(key, 7, data[:len(data)-1], &reading{}), gobin.ErrNotEnoughSpace)
+		r.ErrorIs(Decrypt(key, 7, append(data[:len(data):len(data)], 0xde, 0xad), &reading{}), ErrTrailingData)
+		_, err = NewKey(1, []byte("short"))
+		r.Error(err)
+	})
+}
@@ -0,0 +1,118 @@
+// Package envelope signs and encrypts gobin payloads.
+//
+// An envelope wraps the encoding of a value, typically one generated by
+// cmd/bingen, in a header identifying it and a signature over both:
+//
+//	[version:uint8][algorithm:uint8][type:uint32][fingerprint:uint64][timestamp:time][body:bytes][signature:bytes]
+//
+// every field encoded by gobin.Safe. The signature covers every byte before
+// it. Payload types should be marked gobin:canonical, so that equal values
+// always give the same body and signature.
+//
+// Encrypt and Decrypt keep payloads confidential with AES-GCM instead.
+package envelope
+
+import (
+	"encoding"
+	"errors"
+	"time"
+
+	"github.com/millken/gobin"
+)
+
+// Version is the envelope format written by Seal.
+const Version = 1
+
+var (
+	// ErrVersion is returned by Open for an envelope of an unknown format.
+	ErrVersion = errors.New("unsupported envelope version")
+	// ErrAlgorithm is returned by Open when the envelope is signed with
+	// another algorithm than that of the Verifier.
+	ErrAlgorithm = errors.New("signature algorithm mismatch")
+	// ErrSignature is returned by Open when the signature does not verify.
+	ErrSignature = errors.New("invalid signature")
+	// ErrFingerprint is returned by Open when the payload was encoded from
+	// another schema than that of the value it is decoded into.
+	ErrFingerprint = errors.New("schema fingerprint mismatch")
+	// ErrTrailingData is returned by Open when bytes follow the signature,
+	// and by Decrypt when they follow the ciphertext, so that a message has
+	// a single valid encoding.
+	ErrTrailingData = errors.New("trailing data after signature")
+)
+
+// Header identifies the payload of an envelope.
+type Header struct {
+	// Type is an application defined payload type.
+	Type uint32
+	// Fingerprint is the Fingerprint of the payload type.
+	Fingerprint uint64
+	// Timestamp is the time the envelope was sealed.
+	Timestamp time.Time
+}
+
+// Seal encodes v and returns it in an envelope of type typ signed by s.
+func Seal(s Signer, typ uint32, v encoding.BinaryMarshaler) ([]byte, error) {
+	body, err := v.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	h := Header{
+		Type:        typ,
+		Fingerprint: Fingerprint(v),
+		Timestamp:   time.Now(),
+	}
+	c := gobin.Safe{}
+	data := c.AppendUint8(nil, Version)
+	data = c.AppendUint8(data, uint8(s.Algorithm()))
+	data = c.AppendUint32(data, h.Type)
+	data = c.AppendUint64(data, h.Fingerprint)
+	data = c.AppendTime(data, h.Timestamp)
+	data = c.AppendBytes(data, body)
+	sig, err := s.Sign(data)
+	if err != nil {
+		return nil, err
+	}
+	return c.AppendBytes(data, sig), nil
+}
+
+// Open verifies the envelope data with vf and decodes its payload into v.
+// Nothing is decoded unless the signature verifies, the fingerprint matches
+// that of v and no bytes follow the signature. The caller should check the
+// returned Header, the Type in particular, as v may accept the payload of
+// another type of the same schema.
+func Open(vf Verifier, data []byte, v encoding.BinaryUnmarshaler) (Header, error) {
+	var h Header
+	r := gobin.NewReader(gobin.Safe{}, data)
+	r.Field("Version")
+	if version := r.ReadUint8(); r.Err() == nil && version != Version {
+		return h, ErrVersion
+	}
+	r.Field("Algorithm")
+	if alg := Algorithm(r.ReadUint8()); r.Err() == nil && alg != vf.Algorithm() {
+		return h, ErrAlgorithm
+	}
+	r.Field("Type")
+	h.Type = r.ReadUint32()
+	r.Field("Fingerprint")
+	h.Fingerprint = r.ReadUint64()
+	r.Field("Timestamp")
+	h.Timestamp = r.ReadTime()
+	r.Field("Body")
+	body := r.ReadBytes()
+	signed := r.Offset()
+	r.Field("Signature")
+	sig := r.ReadBytes()
+	if err := r.Err(); err != nil {
+		return h, err
+	}
+	if r.Offset() != len(data) {
+		return h, ErrTrailingData
+	}
+	if !vf.Verify(data[:signed], sig) {
+		return h, ErrSignature
+	}
+	if h.Fingerprint != Fingerprint(v) {
+		return h, ErrFingerprint
+	}
+	return h, v.UnmarshalBinary(body)
+}
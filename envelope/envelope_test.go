@@ -0,0 +1,122 @@
+package envelope
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/millken/gobin"
+	"github.com/stretchr/testify/require"
+)
+
+type reading struct {
+	gobin.Safe
+	Sensor string
+	Values map[string]float64
+}
+
+func (o *reading) MarshalBinary() ([]byte, error)    { return gobin.MarshalCanonical(o) }
+func (o *reading) UnmarshalBinary(data []byte) error { return gobin.Unmarshal(data, o) }
+
+type other struct {
+	Sensor string
+	Count  int
+}
+
+func (o *other) UnmarshalBinary(data []byte) error { return gobin.Unmarshal(data, o) }
+
+func TestEnvelope(t *testing.T) {
+	r := require.New(t)
+	pub, priv, err := ed25519.GenerateKey(nil)
+	r.NoError(err)
+	v := &reading{Sensor: "s1", Values: map[string]float64{"a": 1, "b": 2, "c": 3}}
+
+	for _, tc := range []struct {
+		name string
+		s    Signer
+		vf   Verifier
+	}{
+		{"ed25519", Ed25519Signer(priv), Ed25519Verifier(pub)},
+		{"hmac", HMAC("key"), HMAC("key")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			before := time.Now()
+			data, err := Seal(tc.s, 7, v)
+			r.NoError(err)
+			var v2 reading
+			h, err := Open(tc.vf, data, &v2)
+			r.NoError(err)
+			r.Equal(*v, v2)
+			r.Equal(uint32(7), h.Type)
+			r.Equal(Fingerprint(v), h.Fingerprint)
+			r.WithinDuration(before, h.Timestamp, time.Second)
+
+			for i := range data {
+				bad := append([]byte(nil), data...)
+				bad[i] ^= 1
+				var v3 reading
+				_, err := Open(tc.vf, bad, &v3)
+				r.Error(err, "byte %d", i)
+				r.Zero(v3)
+			}
+			_, err = Open(tc.vf, data[:len(data)-1], &v2)
+			r.ErrorIs(err, gobin.ErrNotEnoughSpace)
+			_, err = Open(tc.vf, append(data[:len(data):len(data)], 0), &v2)
+			r.ErrorIs(err, ErrTrailingData)
+			_, err = Open(tc.vf, data, &other{})
+			r.ErrorIs(err, ErrFingerprint)
+		})
+	}
+	t.Run("should reject another key or algorithm", func(t *testing.T) {
+		data, err := Seal(HMAC("key"), 1, v)
+		r.NoError(err)
+		_, err = Open(HMAC("other"), data, &reading{})
+		r.ErrorIs(err, ErrSignature)
+		_, err = Open(Ed25519Verifier(pub), data, &reading{})
+		r.ErrorIs(err, ErrAlgorithm)
+		_, err = Seal(Ed25519Signer(nil), 1, v)
+		r.ErrorIs(err, ErrKeySize)
+	})
+	t.Run("fingerprint", func(t *testing.T) {
+		r.Equal(Fingerprint(reading{}), Fingerprint(&reading{Sensor: "x"}))
+		r.NotEqual(Fingerprint(reading{}), Fingerprint(other{}))
+		type renamed struct {
+			gobin.Safe
+			Name   string
+			Values map[string]float64
+		}
+		r.NotEqual(Fingerprint(reading{}), Fingerprint(renamed{}))
+		type flags struct {
+			gobin.Safe
+			A, B bool
+		}
+		type packed struct {
+			gobin.Safe `gobin:"packed"`
+			A, B       bool
+		}
+		r.NotEqual(Fingerprint(flags{}), Fingerprint(packed{}))
+		type nibbles struct {
+			gobin.Safe
+			A, B uint8
+		}
+		type bits struct {
+			gobin.Safe
+			A, B uint8 `gobin:"bits=4"`
+		}
+		r.NotEqual(Fingerprint(nibbles{}), Fingerprint(bits{}))
+		type node struct {
+			ID   gobin.GUID
+			At   time.Time
+			Next *node
+		}
+		r.NotZero(Fingerprint(node{}))
+		type DeviceGUID [16]byte
+		type device struct {
+			ID DeviceGUID
+		}
+		type raw struct {
+			ID [16]byte
+		}
+		r.NotEqual(Fingerprint(device{}), Fingerprint(raw{}))
+	})
+}
@@ -0,0 +1,88 @@
+package envelope
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/millken/gobin"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Fingerprint returns a hash of the schema of the type of v: the names and
+// types of its fields, recursively, and the codecs it embeds, with their gobin
+// tags. It changes when a field is added, removed, renamed or retyped, and not
+// with the values of v.
+func Fingerprint(v any) uint64 {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var sb strings.Builder
+	writeSchema(&sb, t, make(map[reflect.Type]bool))
+	sum := sha256.Sum256([]byte(sb.String()))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// writeSchema writes a description of the encoding of t. A struct type seen
+// before is referred to by name, so that recursive types terminate.
+func writeSchema(sb *strings.Builder, t reflect.Type, seen map[reflect.Type]bool) {
+	switch {
+	case t == nil:
+		sb.WriteString("nil")
+		return
+	case t == timeType:
+		sb.WriteString("time")
+		return
+	case gobin.IsGUIDType(t):
+		sb.WriteString("guid")
+		return
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		sb.WriteString("[]")
+		writeSchema(sb, t.Elem(), seen)
+	case reflect.Array:
+		sb.WriteString("[" + strconv.Itoa(t.Len()) + "]")
+		writeSchema(sb, t.Elem(), seen)
+	case reflect.Map:
+		sb.WriteString("map[")
+		writeSchema(sb, t.Key(), seen)
+		sb.WriteString("]")
+		writeSchema(sb, t.Elem(), seen)
+	case reflect.Pointer:
+		sb.WriteString("*")
+		writeSchema(sb, t.Elem(), seen)
+	case reflect.Struct:
+		if seen[t] {
+			sb.WriteString(t.String())
+			return
+		}
+		seen[t] = true
+		sb.WriteString("struct{")
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Anonymous {
+				// the embedded codec, and its packing
+				sb.WriteString(f.Type.String())
+				if tag := f.Tag.Get("gobin"); tag != "" {
+					sb.WriteString(" " + strconv.Quote(tag))
+				}
+			} else {
+				sb.WriteString(f.Name + " ")
+				writeSchema(sb, f.Type, seen)
+				if tag := f.Tag.Get("gobin"); tag != "" {
+					sb.WriteString(" " + strconv.Quote(tag))
+				}
+			}
+			sb.WriteString(";")
+		}
+		sb.WriteString("}")
+	default:
+		sb.WriteString(t.Kind().String())
+	}
+}
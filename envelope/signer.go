@@ -0,0 +1,81 @@
+package envelope
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+)
+
+// ErrKeySize is returned by Ed25519Signer.Sign for a malformed private key.
+var ErrKeySize = errors.New("invalid key size")
+
+// Algorithm identifies the signature algorithm of an envelope.
+type Algorithm uint8
+
+const (
+	Ed25519    Algorithm = iota + 1 // Ed25519Signer and Ed25519Verifier
+	HMACSHA256                      // HMAC
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case Ed25519:
+		return "Ed25519"
+	case HMACSHA256:
+		return "HMAC-SHA256"
+	}
+	return "unknown"
+}
+
+// Signer signs envelopes.
+type Signer interface {
+	Algorithm() Algorithm
+	Sign(msg []byte) ([]byte, error)
+}
+
+// Verifier verifies the signature of envelopes.
+type Verifier interface {
+	Algorithm() Algorithm
+	Verify(msg, sig []byte) bool
+}
+
+// Ed25519Signer signs envelopes with an Ed25519 private key.
+type Ed25519Signer ed25519.PrivateKey
+
+func (Ed25519Signer) Algorithm() Algorithm { return Ed25519 }
+
+func (k Ed25519Signer) Sign(msg []byte) ([]byte, error) {
+	if len(k) != ed25519.PrivateKeySize {
+		return nil, ErrKeySize
+	}
+	return ed25519.Sign(ed25519.PrivateKey(k), msg), nil
+}
+
+// Ed25519Verifier verifies envelopes with an Ed25519 public key.
+type Ed25519Verifier ed25519.PublicKey
+
+func (Ed25519Verifier) Algorithm() Algorithm { return Ed25519 }
+
+func (k Ed25519Verifier) Verify(msg, sig []byte) bool {
+	return len(k) == ed25519.PublicKeySize && ed25519.Verify(ed25519.PublicKey(k), msg, sig)
+}
+
+// HMAC signs and verifies envelopes with HMAC-SHA256 and a shared key.
+type HMAC []byte
+
+func (HMAC) Algorithm() Algorithm { return HMACSHA256 }
+
+func (k HMAC) Sign(msg []byte) ([]byte, error) {
+	return k.sum(msg), nil
+}
+
+func (k HMAC) Verify(msg, sig []byte) bool {
+	return hmac.Equal(k.sum(msg), sig)
+}
+
+func (k HMAC) sum(msg []byte) []byte {
+	h := hmac.New(sha256.New, k)
+	h.Write(msg)
+	return h.Sum(nil)
+}
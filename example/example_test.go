@@ -2,9 +2,14 @@ package example
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/millken/gobin"
 )
 
 func Equal[T comparable](t testing.TB, expected, actual T) {
@@ -94,6 +99,22 @@ func TestA(t *testing.T) {
 	Equal(t, a, b)
 }
 
+func TestBinPackage(t *testing.T) {
+	typ := PackageType_CONFIG
+	a := &BinPackage{
+		Type:      &typ,
+		Data:      []byte("data"),
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 600, time.UTC),
+		Signature: []byte("sig"),
+	}
+	data, err := a.MarshalBinary()
+	NoError(t, err)
+	b := &BinPackage{Type: new(PackageType)}
+	err = b.UnmarshalBinary(data)
+	NoError(t, err)
+	Equal(t, a, b)
+}
+
 /*
 BenchmarkSearchRequest-8   	 7282776	       141.0 ns/op	      48 B/op	       2 allocs/op 2023/8/13
 */
@@ -116,3 +137,261 @@ func BenchmarkSearchRequest(b *testing.B) {
 		//Equal(b, a, c)
 	}
 }
+
+func TestHole(t *testing.T) {
+	a := &Hole{Lat: 51.5, Lon: -0.12, Par: 4, Water: true}
+	data, err := a.MarshalBinary()
+	NoError(t, err)
+	Equal(t, HoleSize, len(data))
+	b := &Hole{}
+	NoError(t, b.UnmarshalBinary(data))
+	Equal(t, a, b)
+
+	data[HoleSize-1] = 2
+	var de *gobin.DecodeError
+	if err := b.UnmarshalBinary(data); !errors.As(err, &de) || de.Field != "Sand" {
+		t.Fatalf("got %v, want an invalid Sand", err)
+	}
+	if err := b.UnmarshalBinary(data[:HoleSize-1]); !errors.Is(err, gobin.ErrNotEnoughSpace) {
+		t.Fatalf("got %v, want ErrNotEnoughSpace", err)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	a := &Features{Name: "Links", Water: true, Trees: true, Holes: 18}
+	data, err := a.MarshalBinary()
+	NoError(t, err)
+	Equal(t, a.Size(), len(data))
+	Equal(t, byte(0b101), data[8+len(a.Name)])
+	ref, err := gobin.Marshal(a)
+	NoError(t, err)
+	Equal(t, string(ref), string(data))
+	Equal(t, string(data), string(marshalSegmented(t, a)))
+	b := &Features{}
+	NoError(t, b.UnmarshalBinary(data))
+	Equal(t, *a, *b)
+
+	data[8+len(a.Name)] |= 0x08
+	var de *gobin.DecodeError
+	if err := b.UnmarshalBinary(data); !errors.As(err, &de) || de.Field != "Water" || !errors.Is(err, gobin.ErrInvalidPadding) {
+		t.Fatalf("got %v, want invalid padding at Water", err)
+	}
+}
+
+func TestRadioPacket(t *testing.T) {
+	a := &RadioPacket{Channel: 5, Reading: 0xabc, Offset: -3, Seq: 42}
+	data, err := a.MarshalBinary()
+	NoError(t, err)
+	Equal(t, RadioPacketSize, len(data))
+	// 3 bits of channel, 12 of reading and 5 of offset take 3 bytes
+	Equal(t, string([]byte{0xe5, 0xd5, 0x0e}), string(data[:3]))
+	ref, err := gobin.Marshal(a)
+	NoError(t, err)
+	Equal(t, string(ref), string(data))
+	Equal(t, string(data), string(marshalSegmented(t, a)))
+	b := &RadioPacket{}
+	NoError(t, b.UnmarshalBinary(data))
+	Equal(t, *a, *b)
+
+	a.Channel = 8
+	if _, err := a.MarshalBinary(); !errors.Is(err, gobin.ErrBitOverflow) {
+		t.Fatalf("got %v, want ErrBitOverflow", err)
+	}
+	var seg gobin.SegmentedBuffer
+	if err := a.MarshalSegmented(&seg); !errors.Is(err, gobin.ErrBitOverflow) {
+		t.Fatalf("got %v, want ErrBitOverflow", err)
+	}
+	a.Channel, a.Offset = 7, 16
+	if _, err := a.MarshalAppend(nil); !errors.Is(err, gobin.ErrBitOverflow) {
+		t.Fatalf("got %v, want ErrBitOverflow", err)
+	}
+
+	data[2] |= 0x10
+	if err := b.UnmarshalBinary(data); !errors.Is(err, gobin.ErrInvalidPadding) {
+		t.Fatalf("got %v, want ErrInvalidPadding", err)
+	}
+}
+
+func TestScores(t *testing.T) {
+	a := &Scores{Course: "Links", ByPlayer: map[string]int32{"ann": 72, "bob": 80}}
+	data, err := a.MarshalBinary()
+	NoError(t, err)
+	b := &Scores{}
+	NoError(t, b.UnmarshalBinary(data))
+	Equal(t, a.Course, b.Course)
+	Equal(t, fmt.Sprint(a.ByPlayer), fmt.Sprint(b.ByPlayer))
+	ref, err := gobin.Marshal(a)
+	NoError(t, err)
+	Equal(t, string(ref), string(data))
+}
+
+func TestSwitches(t *testing.T) {
+	a := &Switches{Course: "Links", Lights: true, Caddies: true}
+	data, err := a.MarshalBinary()
+	NoError(t, err)
+	Equal(t, a.SizeBinary(), len(data))
+	b := &Switches{}
+	NoError(t, b.UnmarshalBinary(data))
+	Equal(t, *a, *b)
+	ref, err := gobin.Marshal(a)
+	NoError(t, err)
+	Equal(t, string(ref), string(data))
+	Equal(t, string(data), string(marshalSegmented(t, a)))
+}
+
+// marshalSegmented returns the encoding of v through a SegmentedBuffer.
+func marshalSegmented(t *testing.T, v gobin.SegmentedMarshaler) []byte {
+	t.Helper()
+	var seg gobin.SegmentedBuffer
+	defer seg.Reset()
+	NoError(t, v.MarshalSegmented(&seg))
+	var out bytes.Buffer
+	_, err := seg.WriteTo(&out)
+	NoError(t, err)
+	return out.Bytes()
+}
+
+// TestPhotoSegmented checks that the image of a Photo is referenced, not
+// copied, when it is encoded into a SegmentedBuffer.
+func TestPhotoSegmented(t *testing.T) {
+	a := &Photo{Name: "dunes", Tags: []string{"sand", "sky"}, Image: bytes.Repeat([]byte{0xa5}, 8<<20)}
+	var seg gobin.SegmentedBuffer
+	defer seg.Reset()
+	NoError(t, a.MarshalSegmented(&seg))
+	Equal(t, a.SizeBinary(), seg.Len())
+	NoError(t, seg.Append(a))
+	Equal(t, 2*a.SizeBinary(), seg.Len())
+
+	// the image is sent as it is when the buffer is written
+	a.Image[0], a.Image[len(a.Image)-1] = 1, 2
+	want, err := a.MarshalBinary()
+	NoError(t, err)
+	var out bytes.Buffer
+	_, err = seg.WriteTo(&out)
+	NoError(t, err)
+	Equal(t, true, bytes.Equal(append(want, want...), out.Bytes()))
+
+	var b Photo
+	NoError(t, b.UnmarshalBinary(out.Bytes()[:len(want)]))
+	Equal(t, a.Name, b.Name)
+	Equal(t, fmt.Sprint(a.Tags), fmt.Sprint(b.Tags))
+	Equal(t, true, bytes.Equal(a.Image, b.Image))
+}
+
+// TestScoresHostileLength checks that a map length past the MaxAlloc limit
+// fails decoding instead of filling a nil map.
+func TestScoresHostileLength(t *testing.T) {
+	for _, l := range []int{10_000_000, 4_000_000} {
+		data := binary.LittleEndian.AppendUint64(make([]byte, 8), uint64(l))
+		if l == 4_000_000 {
+			// enough bytes for an entry each, still too much to allocate
+			data = append(data, make([]byte, l)...)
+		} else {
+			data = append(data, make([]byte, 44)...)
+		}
+		var s Scores
+		err := s.UnmarshalBinary(data)
+		if err == nil {
+			t.Fatalf("length %d: got no error", l)
+		}
+		if l == 4_000_000 && !errors.Is(err, gobin.ErrLimitExceeded) {
+			t.Fatalf("length %d: got %v, want ErrLimitExceeded", l, err)
+		}
+		var ref Scores
+		if err := gobin.Unmarshal(data, &ref); err == nil {
+			t.Fatalf("length %d: reflection got no error", l)
+		}
+	}
+}
+
+// TestPhotoUnmarshalOptions checks that the options given to the generated
+// UnmarshalOptions apply to that call alone.
+func TestPhotoUnmarshalOptions(t *testing.T) {
+	a := &Photo{Name: "dunes", Tags: []string{"sand", "sky"}, Image: []byte("image")}
+	data, err := a.MarshalBinary()
+	NoError(t, err)
+
+	var b Photo
+	NoError(t, b.UnmarshalOptions(data, gobin.DecodeOptions{Mode: gobin.Own}))
+	for i := range data {
+		data[i] = 0xff
+	}
+	Equal(t, a.Name, b.Name)
+	Equal(t, fmt.Sprint(a.Tags), fmt.Sprint(b.Tags))
+	Equal(t, string(a.Image), string(b.Image))
+
+	data, err = a.MarshalBinary()
+	NoError(t, err)
+	err = b.UnmarshalOptions(data, gobin.DecodeOptions{MaxBytesLen: 4})
+	Equal(t, true, errors.Is(err, gobin.ErrLimitExceeded))
+	NoError(t, b.UnmarshalBinary(data))
+
+	h := &Hole{Lat: 51.5, Lon: -0.12, Par: 4, Water: true}
+	data, err = h.MarshalBinary()
+	NoError(t, err)
+	var h2 Hole
+	NoError(t, h2.UnmarshalOptions(data, gobin.DecodeOptions{Mode: gobin.Own}))
+	Equal(t, *h, h2)
+}
+
+/*
+BenchmarkHole compares the straight-line encoding of a fixed size type with
+encoding its fields one by one.
+
+BenchmarkHole/MarshalTo         	747657486	         1.731 ns/op	       0 B/op	       0 allocs/op
+BenchmarkHole/Writer            	32729694	        39.55 ns/op	       0 B/op	       0 allocs/op
+BenchmarkHole/UnmarshalTo       	301284696	         3.892 ns/op	       0 B/op	       0 allocs/op
+BenchmarkHole/Reader            	36915921	        32.95 ns/op	       0 B/op	       0 allocs/op 2026/10/18
+*/
+func BenchmarkHole(b *testing.B) {
+	a := &Hole{Lat: 51.5, Lon: -0.12, Par: 4, Water: true}
+	data := make([]byte, HoleSize)
+	b.Run("MarshalTo", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := a.MarshalTo(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Writer", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := &gobin.Buffer{Bytes: data[:0]}
+		for i := 0; i < b.N; i++ {
+			buf.Bytes = buf.Bytes[:0]
+			w := gobin.NewWriter(a.Unsafe, buf)
+			w.WriteFloat64(a.Lat)
+			w.WriteFloat64(a.Lon)
+			w.WriteUint8(a.Par)
+			w.WriteBool(a.Water)
+			w.WriteBool(a.Sand)
+			if err := w.Err(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("UnmarshalTo", func(b *testing.B) {
+		b.ReportAllocs()
+		c := &Hole{}
+		for i := 0; i < b.N; i++ {
+			if _, err := c.UnmarshalTo(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Reader", func(b *testing.B) {
+		b.ReportAllocs()
+		c := &Hole{}
+		for i := 0; i < b.N; i++ {
+			r := gobin.NewReader(c.Unsafe, data)
+			c.Lat = r.ReadFloat64()
+			c.Lon = r.ReadFloat64()
+			c.Par = r.ReadUint8()
+			c.Water = r.ReadBool()
+			c.Sand = r.ReadBool()
+			if err := r.Err(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package example
+
+import (
+	"fmt"
+	"github.com/millken/gobin"
+)
+
+// Features lists the features of a golf course, its bools packed as bit flags.
+type Features struct {
+	gobin.Safe `gobin:"packed"`
+	// Name is the name of the course.
+	Name string
+	// Water marks the presence of water.
+	Water bool
+	// Sand marks the presence of sand.
+	Sand bool
+	// Trees marks the presence of trees.
+	Trees bool
+	// Holes is the number of holes.
+	Holes uint16
+	// Lit marks a course lit at night.
+	Lit bool
+}
+
+func (o *Features) Size() int {
+	var sz int
+
+	sz += len(o.Name)
+
+	sz += 12
+	return sz
+}
+
+func (o *Features) MarshalTo(data []byte) (int, error) {
+	var (
+		offset, n int
+		err       error
+	)
+	if n, err = o.MarshalString(o.Name, data[offset:]); err != nil {
+		return 0, err
+	}
+	offset += n
+	if n, err = gobin.MarshalPacked(gobin.PackBools(o.Water, o.Sand, o.Trees), 3, data[offset:]); err != nil {
+		return 0, err
+	}
+	offset += n
+	if n, err = o.MarshalUint16(o.Holes, data[offset:]); err != nil {
+		return 0, err
+	}
+	offset += n
+	if n, err = gobin.MarshalPacked(gobin.PackBools(o.Lit), 1, data[offset:]); err != nil {
+		return 0, err
+	}
+	offset += n
+
+	return offset, nil
+}
+
+// MarshalAppend appends the wire-format message to dst.
+func (o *Features) MarshalAppend(dst []byte) ([]byte, error) {
+	var err error
+	dst = o.AppendString(dst, o.Name)
+	dst = gobin.AppendPacked(dst, gobin.PackBools(o.Water, o.Sand, o.Trees), 3)
+	dst = o.AppendUint16(dst, o.Holes)
+	dst = gobin.AppendPacked(dst, gobin.PackBools(o.Lit), 1)
+
+	_ = err
+	return dst, nil
+}
+
+// AppendBinary appends o to data as conform encoding.BinaryAppender.
+func (o *Features) AppendBinary(data []byte) ([]byte, error) {
+	return o.MarshalAppend(data)
+}
+
+// MarshalSegmented appends the encoding of o to seg, which references
+// rather than copies byte slices of at least gobin.SegmentRefThreshold bytes.
+func (o *Features) MarshalSegmented(seg *gobin.SegmentedBuffer) error {
+	w := gobin.NewSegmentedWriter(o.Safe, seg)
+	o.MarshalWriter(w)
+	return w.Err()
+}
+
+// MarshalWriter encodes o with w, which must use the codec of o. Errors are
+// recorded by w.
+func (o *Features) MarshalWriter(w *gobin.Writer) {
+	w.WriteString(o.Name)
+	w.WritePacked(gobin.PackBools(o.Water, o.Sand, o.Trees), 3)
+	w.WriteUint16(o.Holes)
+	w.WritePacked(gobin.PackBools(o.Lit), 1)
+}
+
+// MarshalBinary encodes o as conform encoding.BinaryMarshaler.
+func (o *Features) MarshalBinary() (data []byte, err error) {
+	sz := o.Size()
+	data = make([]byte, sz)
+	n, err := o.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	if n != sz {
+		return nil, fmt.Errorf("%s size / offset different %d : %d", "Marshal", sz, n)
+	}
+	return data, nil
+}
+
+func (o *Features) UnmarshalTo(data []byte) (int, error) {
+	r := gobin.NewReader(o.Safe, data)
+	o.UnmarshalReader(r)
+	if err := r.Err(); err != nil {
+		return 0, err
+	}
+	return r.Offset(), nil
+}
+
+// UnmarshalReader decodes o from r, as conform gobin.ReaderUnmarshaler.
+func (o *Features) UnmarshalReader(r *gobin.Reader) {
+	var l int
+	r.Field("Name")
+	o.Name = r.ReadString()
+	r.Field("Water")
+	{
+		bits := r.ReadPacked(3)
+		o.Water = bits&(1<<0) != 0
+		o.Sand = bits&(1<<1) != 0
+		o.Trees = bits&(1<<2) != 0
+	}
+	r.Field("Holes")
+	o.Holes = r.ReadUint16()
+	r.Field("Lit")
+	{
+		bits := r.ReadPacked(1)
+		o.Lit = bits&(1<<0) != 0
+	}
+
+	_ = l
+}
+
+// Unmarshal decodes data as conform encoding.BinaryUnmarshaler.
+func (o *Features) UnmarshalBinary(data []byte) error {
+	_, err := o.UnmarshalTo(data)
+	return err
+
+}
+
+// UnmarshalOptions decodes o from data, limited by opts rather than gobin.DefaultDecodeOptions.
+func (o *Features) UnmarshalOptions(data []byte, opts gobin.DecodeOptions) error {
+	r := gobin.NewReaderOptions(o.Safe, data, opts)
+	o.UnmarshalReader(r)
+	return r.Err()
+}
@@ -0,0 +1,125 @@
+package example
+
+import (
+	"fmt"
+	"github.com/millken/gobin"
+	"unsafe"
+)
+
+// Hole is a hole of a golf course, with only fixed width fields.
+type Hole struct {
+	gobin.Unsafe
+	// Lat is the latitude of the cup.
+	Lat float64
+	// Lon is the longitude of the cup.
+	Lon float64
+	// Par is the difficulty index.
+	Par uint8
+	// Water marks the presence of water.
+	Water bool
+	// Sand marks the presence of sand.
+	Sand bool
+}
+
+// HoleSize is the encoded size of Hole, which only has fixed width fields.
+const HoleSize = 19
+
+func (o *Hole) Size() int {
+	return HoleSize
+}
+
+func (o *Hole) MarshalTo(data []byte) (int, error) {
+	if len(data) < HoleSize {
+		return 0, gobin.ErrNotEnoughSpace
+	}
+	*(*float64)(unsafe.Pointer(&data[0])) = o.Lat
+	*(*float64)(unsafe.Pointer(&data[8])) = o.Lon
+	*(*uint8)(unsafe.Pointer(&data[16])) = o.Par
+	*(*bool)(unsafe.Pointer(&data[17])) = o.Water
+	*(*bool)(unsafe.Pointer(&data[18])) = o.Sand
+
+	return HoleSize, nil
+}
+
+// MarshalAppend appends the wire-format message to dst.
+func (o *Hole) MarshalAppend(dst []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, HoleSize)...)
+	_, err := o.MarshalTo(dst[n:])
+	return dst, err
+}
+
+// AppendBinary appends o to data as conform encoding.BinaryAppender.
+func (o *Hole) AppendBinary(data []byte) ([]byte, error) {
+	return o.MarshalAppend(data)
+}
+
+// MarshalSegmented appends the encoding of o to seg, which references
+// rather than copies byte slices of at least gobin.SegmentRefThreshold bytes.
+func (o *Hole) MarshalSegmented(seg *gobin.SegmentedBuffer) error {
+	w := gobin.NewSegmentedWriter(o.Unsafe, seg)
+	o.MarshalWriter(w)
+	return w.Err()
+}
+
+// MarshalWriter encodes o with w, which must use the codec of o. Errors are
+// recorded by w.
+func (o *Hole) MarshalWriter(w *gobin.Writer) {
+	w.WriteFloat64(o.Lat)
+	w.WriteFloat64(o.Lon)
+	w.WriteUint8(o.Par)
+	w.WriteBool(o.Water)
+	w.WriteBool(o.Sand)
+}
+
+// MarshalBinary encodes o as conform encoding.BinaryMarshaler.
+func (o *Hole) MarshalBinary() (data []byte, err error) {
+	sz := o.Size()
+	data = make([]byte, sz)
+	n, err := o.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	if n != sz {
+		return nil, fmt.Errorf("%s size / offset different %d : %d", "Marshal", sz, n)
+	}
+	return data, nil
+}
+
+func (o *Hole) UnmarshalTo(data []byte) (int, error) {
+	if len(data) < HoleSize {
+		return 0, &gobin.DecodeError{Type: "Hole", Err: gobin.ErrNotEnoughSpace}
+	}
+	o.Lat = *(*float64)(unsafe.Pointer(&data[0]))
+	o.Lon = *(*float64)(unsafe.Pointer(&data[8]))
+	o.Par = *(*uint8)(unsafe.Pointer(&data[16]))
+	if data[17] > 1 {
+		return 0, &gobin.DecodeError{Offset: 17, Field: "Water", Type: "bool", Err: gobin.ErrInvalidBool}
+	}
+	o.Water = *(*bool)(unsafe.Pointer(&data[17]))
+	if data[18] > 1 {
+		return 0, &gobin.DecodeError{Offset: 18, Field: "Sand", Type: "bool", Err: gobin.ErrInvalidBool}
+	}
+	o.Sand = *(*bool)(unsafe.Pointer(&data[18]))
+
+	return HoleSize, nil
+}
+
+// UnmarshalReader decodes o from r, as conform gobin.ReaderUnmarshaler.
+func (o *Hole) UnmarshalReader(r *gobin.Reader) {
+	r.ReadFunc(o.UnmarshalTo)
+}
+
+// Unmarshal decodes data as conform encoding.BinaryUnmarshaler.
+func (o *Hole) UnmarshalBinary(data []byte) error {
+	_, err := o.UnmarshalTo(data)
+	return err
+
+}
+
+// UnmarshalOptions decodes o from data, limited by opts rather than gobin.DefaultDecodeOptions.
+func (o *Hole) UnmarshalOptions(data []byte, opts gobin.DecodeOptions) error {
+	r := gobin.NewReaderOptions(o.Unsafe, data, opts)
+	o.UnmarshalReader(r)
+	return r.Err()
+}
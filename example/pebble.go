@@ -2,6 +2,7 @@ package example
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/millken/gobin"
 )
@@ -30,7 +31,7 @@ func (o *PackageType) MarshalTo(w []byte) (int, error) {
 // UnmarshalTo reads a wire-format message from data.
 func (o *PackageType) UnmarshalTo(data []byte) (int, error) {
 	if len(data) < 2 {
-		return 0, fmt.Errorf("invalid data size %d", len(data))
+		return 0, &gobin.DecodeError{Type: "PackageType", Err: gobin.ErrNotEnoughSpace}
 	}
 	*o = PackageType(uint16(data[0]) | uint16(data[1])<<8)
 	return 2, nil
@@ -53,7 +54,7 @@ type BinPackage struct {
 	gobin.Safe
 	Type      *PackageType
 	Data      []byte
-	Timestamp uint32
+	Timestamp time.Time
 	Signature []byte
 }
 
@@ -64,7 +65,7 @@ func (o *BinPackage) Size() int {
 	sz += len(o.Data)
 
 	sz += len(o.Signature)
-	sz += 20
+	sz += 28
 	return sz
 }
 
@@ -81,7 +82,7 @@ func (o *BinPackage) MarshalBinary() (data []byte, err error) {
 		return nil, err
 	}
 	offset += n
-	if n, err = o.MarshalUint32(o.Timestamp, data[offset:]); err != nil {
+	if n, err = o.MarshalTime(o.Timestamp, data[offset:]); err != nil {
 		return nil, err
 	}
 	offset += n
@@ -97,29 +98,15 @@ func (o *BinPackage) MarshalBinary() (data []byte, err error) {
 
 // Unmarshal decodes data as conform encoding.BinaryUnmarshaler.
 func (o *BinPackage) UnmarshalBinary(data []byte) error {
-	var (
-		i, n, l int
-		err     error
-	)
-	if i, err = o.Type.UnmarshalTo(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Data, i, err = o.UnmarshalBytes(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Timestamp, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Signature, i, err = o.UnmarshalBytes(data[n:]); err != nil {
-		return err
-	}
-	n += i
+	r := gobin.NewReader(o.Safe, data)
+	var l int
+	r.ReadFunc(o.Type.UnmarshalTo)
+	o.Data = r.ReadBytes()
+	o.Timestamp = r.ReadTime()
+	o.Signature = r.ReadBytes()
 
 	_ = l
-	return nil
+	return r.Err()
 }
 
 type SensorData struct {
@@ -196,36 +183,18 @@ func (o *SensorData) MarshalBinary() (data []byte, err error) {
 		return nil, err
 	}
 	offset += n
-	if n, err = o.MarshalInt(len(o.Gyroscope), data[offset:]); err != nil {
+	if n, err = gobin.MarshalSlice(o.MarshalInt, o.MarshalInt32, o.Gyroscope, data[offset:]); err != nil {
 		return nil, err
 	}
 	offset += n
-	for _, v := range o.Gyroscope {
-		if n, err = o.MarshalInt32(v, data[offset:]); err != nil {
-			return nil, err
-		}
-		offset += n
-	}
-	if n, err = o.MarshalInt(len(o.Accelerometer), data[offset:]); err != nil {
+	if n, err = gobin.MarshalSlice(o.MarshalInt, o.MarshalInt32, o.Accelerometer, data[offset:]); err != nil {
 		return nil, err
 	}
 	offset += n
-	for _, v := range o.Accelerometer {
-		if n, err = o.MarshalInt32(v, data[offset:]); err != nil {
-			return nil, err
-		}
-		offset += n
-	}
-	if n, err = o.MarshalInt(len(o.Random), data[offset:]); err != nil {
+	if n, err = gobin.MarshalSlice(o.MarshalInt, o.MarshalString, o.Random, data[offset:]); err != nil {
 		return nil, err
 	}
 	offset += n
-	for _, v := range o.Random {
-		if n, err = o.MarshalString(v, data[offset:]); err != nil {
-			return nil, err
-		}
-		offset += n
-	}
 	if offset != sz {
 		return nil, fmt.Errorf("%s size / offset different %d : %d", "Marshal", sz, offset)
 	}
@@ -234,95 +203,36 @@ func (o *SensorData) MarshalBinary() (data []byte, err error) {
 
 // Unmarshal decodes data as conform encoding.BinaryUnmarshaler.
 func (o *SensorData) UnmarshalBinary(data []byte) error {
-	var (
-		i, n, l int
-		err     error
-	)
-	if o.Snr, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Vbat, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Latitude, i, err = o.UnmarshalInt32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Longitude, i, err = o.UnmarshalInt32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.GasResistance, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Temperature, i, err = o.UnmarshalInt32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Pressure, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Humidity, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Light, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Temperature2, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if l, i, err = o.UnmarshalInt(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	o.Gyroscope = make([]int32, l)
+	r := gobin.NewReader(o.Safe, data)
+	var l int
+	o.Snr = r.ReadUint32()
+	o.Vbat = r.ReadUint32()
+	o.Latitude = r.ReadInt32()
+	o.Longitude = r.ReadInt32()
+	o.GasResistance = r.ReadUint32()
+	o.Temperature = r.ReadInt32()
+	o.Pressure = r.ReadUint32()
+	o.Humidity = r.ReadUint32()
+	o.Light = r.ReadUint32()
+	o.Temperature2 = r.ReadUint32()
+	l = r.ReadLen()
+	o.Gyroscope = gobin.MakeSlice[[]int32](r, l)
 	for j := range o.Gyroscope {
-		if v, m, err := o.UnmarshalInt32(data[n:]); err != nil {
-			return err
-		} else {
-			i = m
-			o.Gyroscope[j] = v
-		}
-		n += i
-	}
-	if l, i, err = o.UnmarshalInt(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	o.Accelerometer = make([]int32, l)
+		o.Gyroscope[j] = r.ReadInt32()
+	}
+	l = r.ReadLen()
+	o.Accelerometer = gobin.MakeSlice[[]int32](r, l)
 	for j := range o.Accelerometer {
-		if v, m, err := o.UnmarshalInt32(data[n:]); err != nil {
-			return err
-		} else {
-			i = m
-			o.Accelerometer[j] = v
-		}
-		n += i
-	}
-	if l, i, err = o.UnmarshalInt(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	o.Random = make([]string, l)
+		o.Accelerometer[j] = r.ReadInt32()
+	}
+	l = r.ReadLen()
+	o.Random = gobin.MakeSlice[[]string](r, l)
 	for j := range o.Random {
-		if v, m, err := o.UnmarshalString(data[n:]); err != nil {
-			return err
-		} else {
-			i = m
-			o.Random[j] = v
-		}
-		n += i
+		o.Random[j] = r.ReadString()
 	}
 
 	_ = l
-	return nil
+	return r.Err()
 }
 
 type SensorConfig struct {
@@ -390,45 +300,19 @@ func (o *SensorConfig) MarshalBinary() (data []byte, err error) {
 
 // Unmarshal decodes data as conform encoding.BinaryUnmarshaler.
 func (o *SensorConfig) UnmarshalBinary(data []byte) error {
-	var (
-		i, n, l int
-		err     error
-	)
-	if o.BulkUpload, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.DataChannel, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.UploadPeriod, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.BulkUploadSamplingCnt, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.BulkUploadSamplingFreq, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Beep, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.Firmware, i, err = o.UnmarshalString(data[n:]); err != nil {
-		return err
-	}
-	n += i
-	if o.DeviceConfigurable, i, err = o.UnmarshalBool(data[n:]); err != nil {
-		return err
-	}
-	n += i
+	r := gobin.NewReader(o.Safe, data)
+	var l int
+	o.BulkUpload = r.ReadUint32()
+	o.DataChannel = r.ReadUint32()
+	o.UploadPeriod = r.ReadUint32()
+	o.BulkUploadSamplingCnt = r.ReadUint32()
+	o.BulkUploadSamplingFreq = r.ReadUint32()
+	o.Beep = r.ReadUint32()
+	o.Firmware = r.ReadString()
+	o.DeviceConfigurable = r.ReadBool()
 
 	_ = l
-	return nil
+	return r.Err()
 }
 
 type SensorState struct {
@@ -459,17 +343,12 @@ func (o *SensorState) MarshalBinary() (data []byte, err error) {
 
 // Unmarshal decodes data as conform encoding.BinaryUnmarshaler.
 func (o *SensorState) UnmarshalBinary(data []byte) error {
-	var (
-		i, n, l int
-		err     error
-	)
-	if o.State, i, err = o.UnmarshalUint32(data[n:]); err != nil {
-		return err
-	}
-	n += i
+	r := gobin.NewReader(o.Safe, data)
+	var l int
+	o.State = r.ReadUint32()
 
 	_ = l
-	return nil
+	return r.Err()
 }
 
 type SensorConfirm struct {
@@ -502,15 +381,10 @@ func (o *SensorConfirm) MarshalBinary() (data []byte, err error) {
 
 // Unmarshal decodes data as conform encoding.BinaryUnmarshaler.
 func (o *SensorConfirm) UnmarshalBinary(data []byte) error {
-	var (
-		i, n, l int
-		err     error
-	)
-	if o.Owner, i, err = o.UnmarshalString(data[n:]); err != nil {
-		return err
-	}
-	n += i
+	r := gobin.NewReader(o.Safe, data)
+	var l int
+	o.Owner = r.ReadString()
 
 	_ = l
-	return nil
+	return r.Err()
 }
@@ -0,0 +1,15 @@
+package example
+
+import "github.com/millken/gobin"
+
+// Photo is an image with its metadata, large enough to be sent from a
+// SegmentedBuffer. Its methods are generated by cmd/bingen into
+// photo_bin.go.
+//
+//gobin:binary
+type Photo struct {
+	gobin.Safe
+	Name  string
+	Tags  []string
+	Image []byte
+}
@@ -0,0 +1,163 @@
+package example
+
+import (
+	"fmt"
+	"github.com/millken/gobin"
+)
+
+// RadioPacket is a sensor packet sent over radio, with sub-byte fields.
+type RadioPacket struct {
+	gobin.Safe
+	// Channel is the radio channel.
+	Channel uint8 `gobin:"bits=3"`
+	// Reading is the sensor reading.
+	Reading uint16 `gobin:"bits=12"`
+	// Offset is the calibration offset.
+	Offset int8 `gobin:"bits=5"`
+	// Seq is the sequence number.
+	Seq uint32
+}
+
+// RadioPacketSize is the encoded size of RadioPacket, which only has fixed width fields.
+const RadioPacketSize = 7
+
+func (o *RadioPacket) Size() int {
+	return RadioPacketSize
+}
+
+func (o *RadioPacket) MarshalTo(data []byte) (int, error) {
+	var (
+		offset, n int
+		err       error
+	)
+	if len(data)-offset < 3 {
+		return 0, gobin.ErrNotEnoughSpace
+	}
+	{
+		bw := gobin.NewBitWriter(data[offset:offset])
+		if err = bw.WriteBits(uint64(o.Channel), 3); err != nil {
+			return 0, fmt.Errorf("Channel: %w", err)
+		}
+		if err = bw.WriteBits(uint64(o.Reading), 12); err != nil {
+			return 0, fmt.Errorf("Reading: %w", err)
+		}
+		if err = bw.WriteSignedBits(int64(o.Offset), 5); err != nil {
+			return 0, fmt.Errorf("Offset: %w", err)
+		}
+		n = len(bw.Bytes())
+	}
+	offset += n
+	if n, err = o.MarshalUint32(o.Seq, data[offset:]); err != nil {
+		return 0, err
+	}
+	offset += n
+
+	return offset, nil
+}
+
+// MarshalAppend appends the wire-format message to dst.
+func (o *RadioPacket) MarshalAppend(dst []byte) ([]byte, error) {
+	var err error
+	{
+		bw := gobin.NewBitWriter(dst)
+		if err = bw.WriteBits(uint64(o.Channel), 3); err != nil {
+			return nil, fmt.Errorf("Channel: %w", err)
+		}
+		if err = bw.WriteBits(uint64(o.Reading), 12); err != nil {
+			return nil, fmt.Errorf("Reading: %w", err)
+		}
+		if err = bw.WriteSignedBits(int64(o.Offset), 5); err != nil {
+			return nil, fmt.Errorf("Offset: %w", err)
+		}
+		dst = bw.Bytes()
+	}
+	dst = o.AppendUint32(dst, o.Seq)
+
+	_ = err
+	return dst, nil
+}
+
+// AppendBinary appends o to data as conform encoding.BinaryAppender.
+func (o *RadioPacket) AppendBinary(data []byte) ([]byte, error) {
+	return o.MarshalAppend(data)
+}
+
+// MarshalSegmented appends the encoding of o to seg, which references
+// rather than copies byte slices of at least gobin.SegmentRefThreshold bytes.
+func (o *RadioPacket) MarshalSegmented(seg *gobin.SegmentedBuffer) error {
+	w := gobin.NewSegmentedWriter(o.Safe, seg)
+	o.MarshalWriter(w)
+	return w.Err()
+}
+
+// MarshalWriter encodes o with w, which must use the codec of o. Errors are
+// recorded by w.
+func (o *RadioPacket) MarshalWriter(w *gobin.Writer) {
+	w.WriteFunc(3, func(data []byte) (int, error) {
+		var err error
+		bw := gobin.NewBitWriter(data[:0])
+		if err = bw.WriteBits(uint64(o.Channel), 3); err != nil {
+			return 0, fmt.Errorf("Channel: %w", err)
+		}
+		if err = bw.WriteBits(uint64(o.Reading), 12); err != nil {
+			return 0, fmt.Errorf("Reading: %w", err)
+		}
+		if err = bw.WriteSignedBits(int64(o.Offset), 5); err != nil {
+			return 0, fmt.Errorf("Offset: %w", err)
+		}
+		return len(bw.Bytes()), nil
+	})
+	w.WriteUint32(o.Seq)
+}
+
+// MarshalBinary encodes o as conform encoding.BinaryMarshaler.
+func (o *RadioPacket) MarshalBinary() (data []byte, err error) {
+	sz := o.Size()
+	data = make([]byte, sz)
+	n, err := o.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	if n != sz {
+		return nil, fmt.Errorf("%s size / offset different %d : %d", "Marshal", sz, n)
+	}
+	return data, nil
+}
+
+func (o *RadioPacket) UnmarshalTo(data []byte) (int, error) {
+	r := gobin.NewReader(o.Safe, data)
+	o.UnmarshalReader(r)
+	if err := r.Err(); err != nil {
+		return 0, err
+	}
+	return r.Offset(), nil
+}
+
+// UnmarshalReader decodes o from r, as conform gobin.ReaderUnmarshaler.
+func (o *RadioPacket) UnmarshalReader(r *gobin.Reader) {
+	var l int
+	r.Field("Channel")
+	r.ReadBitFields(func(br *gobin.BitReader) {
+		o.Channel = uint8(br.ReadBits(3))
+		o.Reading = uint16(br.ReadBits(12))
+		o.Offset = int8(br.ReadSignedBits(5))
+	})
+	r.Field("Seq")
+	o.Seq = r.ReadUint32()
+
+	_ = l
+}
+
+// Unmarshal decodes data as conform encoding.BinaryUnmarshaler.
+func (o *RadioPacket) UnmarshalBinary(data []byte) error {
+	_, err := o.UnmarshalTo(data)
+	return err
+
+}
+
+// UnmarshalOptions decodes o from data, limited by opts rather than gobin.DefaultDecodeOptions.
+func (o *RadioPacket) UnmarshalOptions(data []byte, opts gobin.DecodeOptions) error {
+	r := gobin.NewReaderOptions(o.Safe, data, opts)
+	o.UnmarshalReader(r)
+	return r.Err()
+}
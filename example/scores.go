@@ -0,0 +1,13 @@
+package example
+
+import "github.com/millken/gobin"
+
+// Scores are the scores of the players of a round. Its methods are generated
+// by cmd/bingen into scores_bin.go.
+//
+//gobin:binary
+type Scores struct {
+	gobin.Safe
+	Course   string
+	ByPlayer map[string]int32
+}
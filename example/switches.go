@@ -0,0 +1,18 @@
+package example
+
+import "github.com/millken/gobin"
+
+// Flag is a setting that is either on or off.
+type Flag bool
+
+// Switches are the settings of a course, its flags packed as bits. Its
+// methods are generated by cmd/bingen into switches_bin.go.
+//
+//gobin:binary
+type Switches struct {
+	gobin.Safe `gobin:"packed"`
+	Course     string
+	Lights     Flag
+	Carts      bool
+	Caddies    Flag
+}
@@ -0,0 +1,176 @@
+package gobin
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sync"
+)
+
+// frame.go writes and reads checksummed frames, one value per frame:
+// [len:uint32][crc:uint32][v:[]byte], the integers in little endian. crc is
+// the CRC-32C of the length and v, so a corrupted length is detected as well.
+// The top bit of the length is the frameCompressed flag, set when v is
+// compressed with compress/flate.
+
+const (
+	checksumFrameHeaderSize = 8
+
+	frameCompressed = 1 << 31
+	// maxFramePayload is the largest payload a checksummed frame holds.
+	maxFramePayload = frameCompressed - 1
+)
+
+// ErrChecksumMismatch is returned when a frame fails its checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// frameChecksum returns the checksum of a frame from the length in hdr and
+// its payload.
+func frameChecksum(hdr, payload []byte) uint32 {
+	return crc32.Update(crc32.Checksum(hdr[:frameHeaderSize], castagnoli), castagnoli, payload)
+}
+
+// crcShift returns crc, the CRC-32C of some bytes A, multiplied by x^(8n):
+// the CRC-32C of A followed by n bytes B is crcShift(crc, n) ^ the CRC-32C of
+// B, and so that of B alone can be told from those of A and AB without
+// reading B again.
+func crcShift(crc uint32, n int) uint32 {
+	t := crcShifts()
+	for k := 0; n > 0; k, n = k+1, n>>1 {
+		if n&1 != 0 {
+			tk := &t[k]
+			crc = tk[0][byte(crc)] ^ tk[1][byte(crc>>8)] ^ tk[2][byte(crc>>16)] ^ tk[3][byte(crc>>24)]
+		}
+	}
+	return crc
+}
+
+// crcShifts returns, for each k, the multiplication by x^(8·2^k) modulo the
+// Castagnoli polynomial as four tables, one per byte of the CRC.
+var crcShifts = sync.OnceValue(func() *[32][4][256]uint32 {
+	t := new([32][4][256]uint32)
+	pow := uint32(1) << 23 // x^8, bit 31 being x^0
+	for k := range t {
+		for j := range t[k] {
+			for v := range t[k][j] {
+				t[k][j][v] = crcMul(pow, uint32(v)<<(8*j))
+			}
+		}
+		pow = crcMul(pow, pow)
+	}
+	return t
+})
+
+// crcMul returns a·b modulo the Castagnoli polynomial, bit 31 of a and b
+// being x^0.
+func crcMul(a, b uint32) uint32 {
+	var p uint32
+	for m := uint32(1) << 31; m != 0; m >>= 1 {
+		if a&m != 0 {
+			p ^= b
+		}
+		if b&1 != 0 {
+			b = b>>1 ^ crc32.Castagnoli
+		} else {
+			b >>= 1
+		}
+	}
+	return p
+}
+
+// WriteFrame appends v to buf as a checksummed frame, e.g. to a Buffer from
+// NewBufferFromPool. Nothing is appended on error.
+func WriteFrame(buf *Buffer, v MarshalerTo) error {
+	return writeFrame(buf, v, 0)
+}
+
+// WriteCompressedFrame appends v to buf as WriteFrame does, compressing it
+// with compress/flate if it takes at least threshold bytes and compression
+// makes it smaller. ReadFrame and Decoder decompress frames transparently.
+func WriteCompressedFrame(buf *Buffer, v MarshalerTo, threshold int) error {
+	return writeFrame(buf, v, threshold)
+}
+
+// writeFrame writes a checksummed frame, compressed from threshold bytes on
+// if threshold is positive.
+func writeFrame(buf *Buffer, v MarshalerTo, threshold int) error {
+	sz := v.SizeBinary()
+	if sz > maxFramePayload {
+		return ErrFrameTooLarge
+	}
+	start := len(buf.Bytes)
+	end := start + checksumFrameHeaderSize + sz
+	buf.Grow(end - start)
+	frame := buf.Bytes[start:end]
+	n, err := v.MarshalTo(frame[checksumFrameHeaderSize:])
+	if err != nil {
+		return err
+	}
+	if n != sz {
+		return fmt.Errorf("%s size / offset different %d : %d", "WriteFrame", sz, n)
+	}
+	if threshold > 0 && sz >= threshold {
+		if m, ok := compressFrame(frame); ok {
+			buf.Bytes = buf.Bytes[:start+m]
+			return nil
+		}
+	}
+	sealFrame(frame, 0)
+	buf.Bytes = buf.Bytes[:end]
+	return nil
+}
+
+// sealFrame writes the header of frame, whose payload follows the header.
+func sealFrame(frame []byte, flags uint32) {
+	l := uint32(len(frame)-checksumFrameHeaderSize) | flags
+	_, _ = marshalSafeInteger32(l, frame)
+	_, _ = marshalSafeInteger32(frameChecksum(frame, frame[checksumFrameHeaderSize:]), frame[frameHeaderSize:])
+}
+
+// ReadFrame decodes the checksummed frame at the start of data into v and
+// returns the size of the frame. v is not decoded if the frame fails its
+// checksum. Compressed frames are decompressed into a new slice, limited to
+// DefaultMaxFrameSize.
+func ReadFrame(data []byte, v UnmarshalerFrom) (n int, err error) {
+	if len(data) < checksumFrameHeaderSize {
+		return 0, ErrNotEnoughSpace
+	}
+	l, _, err := unmarshalSafeInteger32[uint32](data)
+	if err != nil {
+		return 0, err
+	}
+	size := l &^ frameCompressed
+	if uint64(size) > uint64(len(data)-checksumFrameHeaderSize) {
+		return 0, ErrNotEnoughSpace
+	}
+	payload := data[checksumFrameHeaderSize : checksumFrameHeaderSize+int(size)]
+	if err := checkFrame(data, payload); err != nil {
+		return 0, err
+	}
+	if l&frameCompressed != 0 {
+		if payload, err = decompress(nil, payload, DefaultMaxFrameSize); err != nil {
+			return 0, err
+		}
+	}
+	if n, err = v.UnmarshalFrom(payload); err != nil {
+		return 0, err
+	}
+	if n != len(payload) {
+		return 0, fmt.Errorf("%s size / offset different %d : %d", "ReadFrame", len(payload), n)
+	}
+	return checksumFrameHeaderSize + int(size), nil
+}
+
+// checkFrame verifies the checksum in the frame header hdr against payload.
+func checkFrame(hdr, payload []byte) error {
+	crc, _, err := unmarshalSafeInteger32[uint32](hdr[frameHeaderSize:])
+	if err != nil {
+		return err
+	}
+	if crc != frameChecksum(hdr, payload) {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package gobin
+
+import (
+	"bytes"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrame(t *testing.T) {
+	r := require.New(t)
+	values := []*streamValue{
+		{ID: 1, Name: "hello"},
+		{ID: 2, Name: ""},
+		{ID: 3, Name: "hello world"},
+	}
+	t.Run("round trip", func(t *testing.T) {
+		buf := NewBufferFromPool()
+		defer buf.ReturnToPool()
+		for _, v := range values {
+			r.NoError(WriteFrame(buf, v))
+		}
+		data := buf.Bytes
+		for _, v := range values {
+			var v2 streamValue
+			n, err := ReadFrame(data, &v2)
+			r.NoError(err)
+			r.Equal(checksumFrameHeaderSize+v.SizeBinary(), n)
+			r.Equal(*v, v2)
+			data = data[n:]
+		}
+		r.Empty(data)
+	})
+	t.Run("should return ErrChecksumMismatch on a damaged frame", func(t *testing.T) {
+		var buf Buffer
+		r.NoError(WriteFrame(&buf, values[0]))
+		for i := range buf.Bytes {
+			bad := append([]byte(nil), buf.Bytes...)
+			bad[i] ^= 0x10
+			var v streamValue
+			_, err := ReadFrame(bad, &v)
+			r.Error(err, "byte %d", i)
+			r.Zero(v)
+		}
+		bad := append([]byte(nil), buf.Bytes...)
+		bad[len(bad)-1] ^= 1
+		_, err := ReadFrame(bad, &streamValue{})
+		r.ErrorIs(err, ErrChecksumMismatch)
+		_, err = ReadFrame(buf.Bytes[:len(buf.Bytes)-1], &streamValue{})
+		r.ErrorIs(err, ErrNotEnoughSpace)
+	})
+	t.Run("should resynchronise a stream on the next valid frame", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetChecksum(true)
+		for _, v := range values {
+			r.NoError(enc.Encode(v))
+		}
+		data := buf.Bytes()
+		first := checksumFrameHeaderSize + values[0].SizeBinary()
+		r.Equal(first, bytes.Index(data, []byte{2, 0, 0, 0})-checksumFrameHeaderSize)
+		for _, tc := range []struct {
+			damage func([]byte) []byte
+			want   []*streamValue
+		}{
+			{func(b []byte) []byte { b[first-1] ^= 1; return b }, values[1:]},         // payload
+			{func(b []byte) []byte { b[0] = 0xff; return b }, values[1:]},             // length
+			{func(b []byte) []byte { return append([]byte{0, 7}, b...) }, values[0:]}, // garbage
+		} {
+			dec := NewDecoder(bytes.NewReader(tc.damage(append([]byte(nil), data...))))
+			dec.SetChecksum(true)
+			var v streamValue
+			r.Error(dec.Decode(&v))
+			for _, want := range tc.want {
+				r.NoError(dec.Decode(&v))
+				r.Equal(*want, v)
+			}
+			r.ErrorIs(dec.Decode(&v), io.EOF)
+			dec.Release()
+		}
+	})
+	t.Run("should resynchronise over a large damaged frame in linear time", func(t *testing.T) {
+		// Most of the damaged frame reads as lengths up to 1 MiB, each of
+		// them a candidate frame to check.
+		big := &streamValue{ID: 9, Name: strings.Repeat("\x00\x00\x10\x00", 1<<20-4)}
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetChecksum(true)
+		r.NoError(enc.Encode(big))
+		r.NoError(enc.Encode(values[0]))
+		data := buf.Bytes()
+		data[checksumFrameHeaderSize] ^= 1
+		dec := NewDecoder(bytes.NewReader(data))
+		dec.SetChecksum(true)
+		defer dec.Release()
+		start := time.Now()
+		var v streamValue
+		r.ErrorIs(dec.Decode(&v), ErrChecksumMismatch)
+		r.NoError(dec.Decode(&v))
+		r.Equal(*values[0], v)
+		r.Less(time.Since(start), 5*time.Second)
+		r.ErrorIs(dec.Decode(&v), io.EOF)
+	})
+	t.Run("should resynchronise a live stream without waiting for a damaged length", func(t *testing.T) {
+		var buf bytes.Buffer
+		// A frame failing its checksum, holding lengths of 1 MiB and 4 KiB.
+		buf.Write([]byte{4, 0, 0, 0, 0, 0, 0, 0, 0, 0x10, 0, 0})
+		enc := NewEncoder(&buf)
+		enc.SetChecksum(true)
+		for _, v := range values {
+			r.NoError(enc.Encode(v))
+		}
+		pr, pw := io.Pipe()
+		defer pw.Close()
+		go func() { _, _ = pw.Write(buf.Bytes()) }()
+		dec := NewDecoder(pr)
+		dec.SetChecksum(true)
+		defer dec.Release()
+		r.ErrorIs(dec.Decode(&streamValue{}), ErrChecksumMismatch)
+		done := make(chan error, 1)
+		var v streamValue
+		go func() { done <- dec.Decode(&v) }()
+		select {
+		case err := <-done:
+			r.NoError(err)
+			r.Equal(*values[0], v)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Decode blocked on a damaged length")
+		}
+	})
+	t.Run("should report a truncated stream", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetChecksum(true)
+		r.NoError(enc.Encode(values[0]))
+		dec := NewDecoder(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+		dec.SetChecksum(true)
+		defer dec.Release()
+		r.ErrorIs(dec.Decode(&streamValue{}), io.ErrUnexpectedEOF)
+		r.Error(dec.Decode(&streamValue{}))
+	})
+}
+
+func TestCRCShift(t *testing.T) {
+	r := require.New(t)
+	data := []byte(strings.Repeat("gobin frames are checksummed. ", 100))
+	for _, i := range []int{0, 1, 7, 100, len(data)} {
+		a, b := data[:i], data[i:]
+		crcA := crc32.Checksum(a, castagnoli)
+		r.Equal(crc32.Checksum(data, castagnoli), crcShift(crcA, len(b))^crc32.Checksum(b, castagnoli), "split at %d", i)
+	}
+}
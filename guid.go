@@ -0,0 +1,90 @@
+package gobin
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// ErrInvalidGUID is returned by ParseGUID for a malformed string.
+var ErrInvalidGUID = errors.New("invalid GUID")
+
+// GUID is a globally unique identifier, held in the byte order of its string
+// form 00112233-4455-6677-8899-aabbccddeeff, as RFC 4122 UUIDs are.
+//
+// Every codec encodes it as 16 bytes in the order of .NET's
+// Guid.ToByteArray: the first three groups little endian, the last two as
+// they are. 00112233-4455-6677-8899-aabbccddeeff is encoded as
+// 33 22 11 00 55 44 77 66 88 99 aa bb cc dd ee ff.
+type GUID [16]byte
+
+// String returns g as 00112233-4455-6677-8899-aabbccddeeff.
+func (g GUID) String() string {
+	var b [36]byte
+	hex.Encode(b[0:8], g[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], g[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], g[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], g[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], g[10:])
+	return string(b[:])
+}
+
+// ParseGUID parses a GUID formatted as by String, optionally enclosed in
+// braces as .NET formats them.
+func ParseGUID(s string) (g GUID, err error) {
+	if len(s) == 38 && s[0] == '{' && s[37] == '}' {
+		s = s[1:37]
+	}
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return g, ErrInvalidGUID
+	}
+	if _, err := hex.Decode(g[:], []byte(strings.ReplaceAll(s, "-", ""))); err != nil {
+		return GUID{}, ErrInvalidGUID
+	}
+	return g, nil
+}
+
+// guidOrder maps the bytes of a GUID to their position in the encoding.
+var guidOrder = [16]int{3, 2, 1, 0, 5, 4, 7, 6, 8, 9, 10, 11, 12, 13, 14, 15}
+
+func marshalGUID(v GUID, bs []byte) (n int, err error) {
+	if len(bs) < 16 {
+		return 0, ErrNotEnoughSpace
+	}
+	for i, j := range guidOrder {
+		bs[i] = v[j]
+	}
+	return 16, nil
+}
+
+func unmarshalGUID(bs []byte) (v GUID, n int, err error) {
+	if len(bs) < 16 {
+		return v, 0, ErrNotEnoughSpace
+	}
+	for i, j := range guidOrder {
+		v[j] = bs[i]
+	}
+	return v, 16, nil
+}
+
+func appendGUID(dst []byte, v GUID) []byte {
+	for _, j := range guidOrder {
+		dst = append(dst, v[j])
+	}
+	return dst
+}
+
+// IsGUIDType reports whether t is encoded as a GUID rather than as an array
+// of bytes: a [16]byte type named GUID or UUID, or whose name ends with
+// either, such as uuid.UUID or a DeviceGUID. cmd/bingen applies the same rule.
+func IsGUIDType(t reflect.Type) bool {
+	if t.Kind() != reflect.Array || t.Len() != 16 || t.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	return strings.HasSuffix(t.Name(), "GUID") || strings.HasSuffix(t.Name(), "UUID")
+}
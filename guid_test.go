@@ -0,0 +1,58 @@
+package gobin
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGUID(t *testing.T) {
+	r := require.New(t)
+	g, err := ParseGUID("00112233-4455-6677-8899-aabbccddeeff")
+	r.NoError(err)
+	r.Equal(GUID{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}, g)
+	r.Equal("00112233-4455-6677-8899-aabbccddeeff", g.String())
+	t.Run("Guid.ToByteArray order", func(t *testing.T) {
+		want := []byte{0x33, 0x22, 0x11, 0x00, 0x55, 0x44, 0x77, 0x66, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+		for _, c := range []fullCodec{Safe{}, Unsafe{}, BigEndian{}, Varint{}} {
+			bs := make([]byte, 16)
+			n, err := c.MarshalGUID(g, bs)
+			r.NoError(err)
+			r.Equal(16, n)
+			r.Equal(want, bs)
+			r.Equal(want, c.AppendGUID(nil, g))
+			g2, n, err := c.UnmarshalGUID(bs)
+			r.NoError(err)
+			r.Equal(16, n)
+			r.Equal(g, g2)
+			_, err = c.MarshalGUID(g, bs[:15])
+			r.ErrorIs(err, ErrNotEnoughSpace)
+			_, _, err = c.UnmarshalGUID(bs[:15])
+			r.ErrorIs(err, ErrNotEnoughSpace)
+		}
+	})
+	t.Run("parse", func(t *testing.T) {
+		g2, err := ParseGUID("{00112233-4455-6677-8899-AABBCCDDEEFF}")
+		r.NoError(err)
+		r.Equal(g, g2)
+		for _, s := range []string{"", "00112233445566778899aabbccddeeff", "00112233-4455-6677-8899-aabbccddeefg", "{00112233-4455-6677-8899-aabbccddeeff"} {
+			_, err = ParseGUID(s)
+			r.ErrorIs(err, ErrInvalidGUID, s)
+		}
+	})
+	t.Run("reflection", func(t *testing.T) {
+		type DeviceUUID [16]byte
+		v := struct {
+			ID  DeviceUUID
+			Raw [16]byte
+		}{DeviceUUID(g), g}
+		bs, err := Marshal(v)
+		r.NoError(err)
+		r.Equal(AppendGUID(nil, g), bs[:16])
+		r.Equal(g[:], bs[16:])
+		r.True(IsGUIDType(reflect.TypeOf(v.ID)))
+		r.False(IsGUIDType(reflect.TypeOf(v.Raw)))
+		r.False(IsGUIDType(reflect.TypeOf([15]byte{})))
+	})
+}
@@ -1,14 +1,19 @@
 package gobin
 
-import "encoding"
+import (
+	"encoding"
+	"time"
+)
 
 type Marshaler interface {
 	encoding.BinaryMarshaler
 	MarshalBool(bool, []byte) (int, error)
+	MarshalInt(int, []byte) (int, error)
 	MarshalInt8(int8, []byte) (int, error)
 	MarshalInt16(int16, []byte) (int, error)
 	MarshalInt32(int32, []byte) (int, error)
 	MarshalInt64(int64, []byte) (int, error)
+	MarshalUint(uint, []byte) (int, error)
 	MarshalUint8(uint8, []byte) (int, error)
 	MarshalUint16(uint16, []byte) (int, error)
 	MarshalUint32(uint32, []byte) (int, error)
@@ -18,15 +23,20 @@ type Marshaler interface {
 	MarshalString(string, []byte) (int, error)
 	MarshalByte(byte, []byte) (int, error)
 	MarshalBytes([]byte, []byte) (int, error)
+	MarshalTime(time.Time, []byte) (int, error)
+	MarshalDuration(time.Duration, []byte) (int, error)
+	MarshalGUID(GUID, []byte) (int, error)
 }
 
 type Unmarshaler interface {
 	encoding.BinaryUnmarshaler
 	UnmarshalBool([]byte) (bool, int, error)
+	UnmarshalInt([]byte) (int, int, error)
 	UnmarshalInt8([]byte) (int8, int, error)
 	UnmarshalInt16([]byte) (int16, int, error)
 	UnmarshalInt32([]byte) (int32, int, error)
 	UnmarshalInt64([]byte) (int64, int, error)
+	UnmarshalUint([]byte) (uint, int, error)
 	UnmarshalUint8([]byte) (uint8, int, error)
 	UnmarshalUint16([]byte) (uint16, int, error)
 	UnmarshalUint32([]byte) (uint32, int, error)
@@ -36,6 +46,22 @@ type Unmarshaler interface {
 	UnmarshalString([]byte) (string, int, error)
 	UnmarshalByte([]byte) (byte, int, error)
 	UnmarshalBytes([]byte) ([]byte, int, error)
+	UnmarshalTime([]byte) (time.Time, int, error)
+	UnmarshalDuration([]byte) (time.Duration, int, error)
+	UnmarshalGUID([]byte) (GUID, int, error)
+}
+
+// StringBorrower is implemented by codecs decoding strings that alias their
+// input, as Reader does in the Borrow and Own decode modes. Every codec of
+// the package implements it.
+type StringBorrower interface {
+	BorrowString([]byte) (string, int, error)
+}
+
+// stringAliaser is implemented by codecs whose UnmarshalString aliases its
+// input, so that Reader borrows their strings in CodecMode.
+type stringAliaser interface {
+	aliasesStrings()
 }
 
 // Integer64 is a constraint that permits any 64-bit integer type.
@@ -73,3 +99,65 @@ type MarshallerFn[T any] func(t T, bs []byte) (n int, err error)
 
 // UnmarshallerFn is a functional implementation of the Unmarshaller interface.
 type UnmarshallerFn[T any] func(bs []byte) (t T, n int, err error)
+
+// MarshalerTo is implemented by types that know their encoded size and can
+// encode themselves into a caller provided slice, as generated by cmd/bingen.
+type MarshalerTo interface {
+	SizeBinary() int
+	MarshalTo([]byte) (int, error)
+}
+
+// UnmarshalerFrom is implemented by types that decode themselves from the
+// start of a slice and report the number of bytes read.
+type UnmarshalerFrom interface {
+	UnmarshalFrom([]byte) (int, error)
+}
+
+// ReaderUnmarshaler is implemented by types that decode themselves from a
+// Reader, sharing its limits and field path with the enclosing type.
+type ReaderUnmarshaler interface {
+	UnmarshalReader(*Reader)
+}
+
+// SegmentedMarshaler is implemented by types that encode themselves into a
+// SegmentedBuffer, referencing their large byte slices rather than copying
+// them, as generated by cmd/bingen.
+type SegmentedMarshaler interface {
+	MarshalSegmented(*SegmentedBuffer) error
+}
+
+// Appender is implemented by codecs that append the encoding of a value to
+// a slice, growing it as needed.
+type Appender interface {
+	AppendBool([]byte, bool) []byte
+	AppendInt([]byte, int) []byte
+	AppendInt8([]byte, int8) []byte
+	AppendInt16([]byte, int16) []byte
+	AppendInt32([]byte, int32) []byte
+	AppendInt64([]byte, int64) []byte
+	AppendUint([]byte, uint) []byte
+	AppendUint8([]byte, uint8) []byte
+	AppendUint16([]byte, uint16) []byte
+	AppendUint32([]byte, uint32) []byte
+	AppendUint64([]byte, uint64) []byte
+	AppendFloat32([]byte, float32) []byte
+	AppendFloat64([]byte, float64) []byte
+	AppendString([]byte, string) []byte
+	AppendByte([]byte, byte) []byte
+	AppendBytes([]byte, []byte) []byte
+	AppendTime([]byte, time.Time) []byte
+	AppendDuration([]byte, time.Duration) []byte
+	AppendGUID([]byte, GUID) []byte
+}
+
+// MarshalAppender is implemented by types that append their encoding to a
+// slice, as generated by cmd/bingen and cmd/gobin.
+type MarshalAppender interface {
+	MarshalAppend([]byte) ([]byte, error)
+}
+
+// BinaryAppender has the method set of encoding.BinaryAppender, added in Go
+// 1.24, so generated types satisfy both without requiring a newer Go.
+type BinaryAppender interface {
+	AppendBinary([]byte) ([]byte, error)
+}
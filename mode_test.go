@@ -0,0 +1,110 @@
+package gobin
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+// aliases reports whether p, of length n, points into data.
+func aliases(data []byte, p unsafe.Pointer, n int) bool {
+	if n == 0 || len(data) == 0 {
+		return false
+	}
+	start, end := uintptr(unsafe.Pointer(&data[0])), uintptr(unsafe.Pointer(&data[0]))+uintptr(len(data))
+	return uintptr(p) < end && uintptr(p)+uintptr(n) > start
+}
+
+type modeValue struct {
+	Name  string
+	Data  []byte
+	Tags  []string
+	Blobs map[string][]byte
+}
+
+type modeUnsafeValue struct {
+	Unsafe
+	Name  string
+	Data  []byte
+	Tags  []string
+	Blobs map[string][]byte
+}
+
+func TestDecodeMode(t *testing.T) {
+	r := require.New(t)
+	for _, c := range []fullCodec{Safe{}, Unsafe{}, BigEndian{}, Varint{}} {
+		var _ StringBorrower = c.(StringBorrower)
+		var data []byte
+		data = c.AppendString(data, "hello")
+		data = c.AppendBytes(data, []byte("world"))
+		read := func(mode DecodeMode) (string, []byte) {
+			rd := NewReaderOptions(c, data, DecodeOptions{Mode: mode})
+			s, b := rd.ReadString(), rd.ReadBytes()
+			r.NoError(rd.Err())
+			r.Equal("hello", s)
+			r.Equal([]byte("world"), b)
+			return s, b
+		}
+		t.Run("borrow should alias the data", func(t *testing.T) {
+			s, b := read(Borrow)
+			r.True(aliases(data, unsafe.Pointer(unsafe.StringData(s)), len(s)), "%T", c)
+			r.True(aliases(data, unsafe.Pointer(&b[0]), len(b)), "%T", c)
+		})
+		t.Run("own should copy the data", func(t *testing.T) {
+			s, b := read(Own)
+			r.False(aliases(data, unsafe.Pointer(unsafe.StringData(s)), len(s)), "%T", c)
+			r.False(aliases(data, unsafe.Pointer(&b[0]), len(b)), "%T", c)
+		})
+	}
+	t.Run("only copies should count against MaxAlloc", func(t *testing.T) {
+		for _, tc := range []struct {
+			c      fullCodec
+			mode   DecodeMode
+			copies bool
+		}{
+			{Safe{}, Borrow, false},
+			{Unsafe{}, Borrow, false},
+			{Safe{}, Own, true},
+			{Unsafe{}, Own, true},
+			{Safe{}, CodecMode, true},
+			{BigEndian{}, CodecMode, true},
+			{Varint{}, CodecMode, true},
+			{Unsafe{}, CodecMode, false},
+		} {
+			data := tc.c.AppendString(nil, "hello")
+			rd := NewReaderOptions(tc.c, data, DecodeOptions{Mode: tc.mode, MaxAlloc: 4})
+			s := rd.ReadString()
+			if tc.copies {
+				r.ErrorIs(rd.Err(), ErrLimitExceeded, "%T %d", tc.c, tc.mode)
+				r.Equal("", s)
+				continue
+			}
+			r.NoError(rd.Err(), "%T %d", tc.c, tc.mode)
+			r.Equal("hello", s)
+			r.True(aliases(data, unsafe.Pointer(unsafe.StringData(s)), len(s)), "%T %d", tc.c, tc.mode)
+		}
+	})
+	t.Run("own should not alias a reused buffer", func(t *testing.T) {
+		v := modeValue{
+			Name:  "name",
+			Data:  []byte("data"),
+			Tags:  []string{"a", "b"},
+			Blobs: map[string][]byte{"k": []byte("blob")},
+		}
+		check := func(v, v2 any) {
+			data, err := Marshal(v)
+			r.NoError(err)
+			buf := NewBufferFromPool()
+			buf.Bytes = append(buf.Bytes, data...)
+			r.NoError(UnmarshalOptions(buf.Bytes, v2, DecodeOptions{Mode: Own}))
+			for i := range buf.Bytes {
+				buf.Bytes[i] = 0xff
+			}
+			buf.ReturnToPool()
+			r.Equal(v, v2)
+		}
+		check(&v, &modeValue{})
+		check(&modeUnsafeValue{Name: v.Name, Data: v.Data, Tags: v.Tags, Blobs: v.Blobs}, &modeUnsafeValue{})
+	})
+}
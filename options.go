@@ -0,0 +1,117 @@
+package gobin
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// ErrLimitExceeded is matched by errors.Is for every LimitError.
+var ErrLimitExceeded = errors.New("decode limit exceeded")
+
+// DecodeOptions limits the resources a Reader may spend on a single payload,
+// so that a hostile length prefix cannot make the decoder allocate more than
+// the caller is willing to. A zero field means no limit.
+type DecodeOptions struct {
+	// MaxBytesLen is the maximum length of a string or []byte.
+	MaxBytesLen int
+	// MaxCollectionLen is the maximum length of a slice or map.
+	MaxCollectionLen int
+	// MaxDepth is the maximum nesting depth of types decoded with
+	// Reader.ReadValue.
+	MaxDepth int
+	// MaxAlloc is the maximum number of bytes allocated for strings, slices
+	// and maps.
+	MaxAlloc int
+	// Mode chooses whether strings and byte slices alias the data.
+	Mode DecodeMode
+}
+
+// DecodeMode chooses whether decoded strings and byte slices may share memory
+// with the data they are decoded from.
+type DecodeMode uint8
+
+const (
+	// CodecMode leaves the choice to the codec: the UnmarshalBytes method of
+	// every codec and the UnmarshalString method of Unsafe alias the data,
+	// the UnmarshalString method of the other codecs copies it.
+	CodecMode DecodeMode = iota
+	// Borrow lets strings and byte slices alias the data, saving a copy
+	// each. The data must then be neither modified nor reused, e.g. by
+	// returning its Buffer to the pool, while the values are in use.
+	Borrow
+	// Own copies every string and byte slice, so that the data can be
+	// reused as soon as decoding returns.
+	Own
+)
+
+// DefaultDecodeOptions are the options used by NewReader, and so by
+// generated code.
+var DefaultDecodeOptions = DecodeOptions{
+	MaxDepth: 64,
+	MaxAlloc: 64 << 20,
+}
+
+// LimitError is returned, wrapped in a DecodeError, when decoding would
+// exceed one of the DecodeOptions.
+type LimitError struct {
+	Limit string // "bytes length", "collection length", "depth" or "alloc"
+	Len   int    // requested value
+	Max   int    // configured limit
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s %d exceeds limit %d", e.Limit, e.Len, e.Max)
+}
+
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// allocate accounts for l values of size bytes against MaxAlloc. It reports
+// whether the allocation may go ahead.
+func (r *Reader) allocate(l, size int) bool {
+	if r.err != nil {
+		return false
+	}
+	max := r.opts.MaxAlloc
+	if max <= 0 || size <= 0 {
+		return true
+	}
+	if l > (max-r.alloc)/size {
+		n := math.MaxInt
+		if l <= (math.MaxInt-r.alloc)/size {
+			n = r.alloc + l*size
+		}
+		r.fail("", &LimitError{Limit: "alloc", Len: n, Max: max})
+		return false
+	}
+	r.alloc += l * size
+	return true
+}
+
+// MakeSlice returns a slice of length l, as read by Reader.ReadLen, unless
+// it would exceed the MaxAlloc limit of r, in which case it records the
+// error and returns nil.
+func MakeSlice[S ~[]E, E any](r *Reader, l int) S {
+	var e E
+	if !r.allocate(l, int(unsafe.Sizeof(e))) {
+		return nil
+	}
+	return make(S, l)
+}
+
+// MakeMap returns a map sized for l entries, as read by Reader.ReadLen,
+// unless it would exceed the MaxAlloc limit of r, in which case it records
+// the error and returns nil.
+func MakeMap[M ~map[K]V, K comparable, V any](r *Reader, l int) M {
+	var (
+		k K
+		v V
+	)
+	if !r.allocate(l, int(unsafe.Sizeof(k)+unsafe.Sizeof(v))) {
+		return nil
+	}
+	return make(M, l)
+}
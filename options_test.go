@@ -0,0 +1,86 @@
+package gobin
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeOptions(t *testing.T) {
+	r := require.New(t)
+	v := Safe{}
+	t.Run("should limit the length of a collection", func(t *testing.T) {
+		bs := make([]byte, 8)
+		_, err := v.MarshalInt(11, bs)
+		r.NoError(err)
+		rd := NewReaderOptions(v, bs, DecodeOptions{MaxCollectionLen: 10})
+		r.Equal(0, rd.ReadLen())
+		r.ErrorIs(rd.Err(), ErrLimitExceeded)
+		var le *LimitError
+		r.ErrorAs(rd.Err(), &le)
+		r.Equal(&LimitError{Limit: "collection length", Len: 11, Max: 10}, le)
+	})
+	t.Run("should limit the length of strings and bytes", func(t *testing.T) {
+		bs := make([]byte, 100)
+		n, err := v.MarshalString("hello world", bs)
+		r.NoError(err)
+		_, err = v.MarshalBytes([]byte("hello world"), bs[n:])
+		r.NoError(err)
+		rd := NewReaderOptions(v, bs, DecodeOptions{MaxBytesLen: 5})
+		r.Equal("", rd.ReadString())
+		r.ErrorIs(rd.Err(), ErrLimitExceeded)
+		rd = NewReaderOptions(v, bs[n:], DecodeOptions{MaxBytesLen: 5})
+		r.Nil(rd.ReadBytes())
+		r.ErrorIs(rd.Err(), ErrLimitExceeded)
+	})
+	t.Run("should check a string before copying it", func(t *testing.T) {
+		bs := v.AppendString(nil, strings.Repeat("x", 1<<20))
+		for _, opts := range []DecodeOptions{{MaxBytesLen: 16}, {MaxAlloc: 16}, {MaxBytesLen: 16, Mode: Own}} {
+			var m0, m1 runtime.MemStats
+			runtime.ReadMemStats(&m0)
+			rd := NewReaderOptions(v, bs, opts)
+			r.Equal("", rd.ReadString())
+			runtime.ReadMemStats(&m1)
+			r.ErrorIs(rd.Err(), ErrLimitExceeded)
+			r.Less(m1.TotalAlloc-m0.TotalAlloc, uint64(64<<10), "%+v", opts)
+		}
+	})
+	t.Run("should limit the total allocation", func(t *testing.T) {
+		rd := NewReaderOptions(v, nil, DecodeOptions{MaxAlloc: 100})
+		r.Len(MakeSlice[[]uint64](rd, 10), 10)
+		r.NoError(rd.Err())
+		r.NotNil(MakeMap[map[uint8]uint8](rd, 10))
+		r.NoError(rd.Err())
+		r.Nil(MakeSlice[[]uint64](rd, 1))
+		r.ErrorIs(rd.Err(), ErrLimitExceeded)
+
+		rd = NewReader(v, nil)
+		r.Nil(MakeSlice[[]uint64](rd, 1<<60))
+		r.ErrorIs(rd.Err(), ErrLimitExceeded)
+	})
+	t.Run("should limit the nesting depth", func(t *testing.T) {
+		bs := make([]byte, 8)
+		rd := NewReaderOptions(v, bs, DecodeOptions{MaxDepth: 2})
+		var depth int
+		var nested readerFunc
+		nested = func(rd *Reader) {
+			depth++
+			rd.Field("Child")
+			rd.ReadValue(nested)
+		}
+		rd.ReadValue(nested)
+		r.Equal(2, depth)
+		r.ErrorIs(rd.Err(), ErrLimitExceeded)
+		var de *DecodeError
+		r.ErrorAs(rd.Err(), &de)
+		r.Equal("Child.Child", de.Field)
+	})
+}
+
+type readerFunc func(*Reader)
+
+func (fn readerFunc) UnmarshalReader(r *Reader) {
+	fn(r)
+}
@@ -0,0 +1,69 @@
+package gobin
+
+import "errors"
+
+// ErrInvalidPadding is returned when the unused bits of packed bools are set.
+var ErrInvalidPadding = errors.New("packed bools padding bits set")
+
+// Packed bools are the opt-in encoding of consecutive bool fields, selected
+// by a gobin:"packed" tag on the embedded codec or by the go_packed schema
+// option. A run of n bools takes PackedSize(n) bytes whatever the codec, the
+// i-th bool being bit i%8 of byte i/8. The unused high bits of the last byte
+// are zero, so a single packed bool is encoded as an unpacked one.
+//
+// The functions below take runs of 1 to MaxPacked bools, generators split
+// longer runs.
+
+// MaxPacked is the most bools packed into the uint64 of PackBools.
+const MaxPacked = 64
+
+// PackedSize returns the encoded size of n packed bools.
+func PackedSize(n int) int {
+	return (n + 7) / 8
+}
+
+// PackBools returns v as bit flags, v[i] being bit i.
+func PackBools(v ...bool) (bits uint64) {
+	for i, b := range v {
+		if b {
+			bits |= 1 << i
+		}
+	}
+	return bits
+}
+
+// MarshalPacked encodes the n low bits of bits.
+func MarshalPacked(bits uint64, n int, bs []byte) (int, error) {
+	size := PackedSize(n)
+	if len(bs) < size {
+		return 0, ErrNotEnoughSpace
+	}
+	for i := 0; i < size; i++ {
+		bs[i] = byte(bits >> (8 * i))
+	}
+	return size, nil
+}
+
+// AppendPacked appends the n low bits of bits to dst.
+func AppendPacked(dst []byte, bits uint64, n int) []byte {
+	for i := 0; i < PackedSize(n); i++ {
+		dst = append(dst, byte(bits>>(8*i)))
+	}
+	return dst
+}
+
+// UnmarshalPacked decodes n packed bools, returning ErrInvalidPadding if a
+// bit above the n-th is set.
+func UnmarshalPacked(bs []byte, n int) (bits uint64, m int, err error) {
+	size := PackedSize(n)
+	if len(bs) < size {
+		return 0, 0, ErrNotEnoughSpace
+	}
+	for i := 0; i < size; i++ {
+		bits |= uint64(bs[i]) << (8 * i)
+	}
+	if n < MaxPacked && bits>>n != 0 {
+		return 0, 0, ErrInvalidPadding
+	}
+	return bits, size, nil
+}
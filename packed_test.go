@@ -0,0 +1,106 @@
+package gobin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacked(t *testing.T) {
+	r := require.New(t)
+	r.Equal(0, PackedSize(0))
+	r.Equal(1, PackedSize(1))
+	r.Equal(1, PackedSize(8))
+	r.Equal(2, PackedSize(9))
+	r.Equal(8, PackedSize(MaxPacked))
+
+	bits := PackBools(true, false, true, false, false, false, false, false, true)
+	r.Equal(uint64(0x105), bits)
+
+	bs := make([]byte, 2)
+	n, err := MarshalPacked(bits, 9, bs)
+	r.NoError(err)
+	r.Equal(2, n)
+	r.Equal([]byte{0x05, 0x01}, bs)
+	r.Equal([]byte{7, 0x05, 0x01}, AppendPacked([]byte{7}, bits, 9))
+	_, err = MarshalPacked(bits, 9, bs[:1])
+	r.ErrorIs(err, ErrNotEnoughSpace)
+
+	got, n, err := UnmarshalPacked(bs, 9)
+	r.NoError(err)
+	r.Equal(2, n)
+	r.Equal(bits, got)
+	_, _, err = UnmarshalPacked(bs[:1], 9)
+	r.ErrorIs(err, ErrNotEnoughSpace)
+	_, _, err = UnmarshalPacked([]byte{0x05, 0x03}, 9)
+	r.ErrorIs(err, ErrInvalidPadding)
+
+	// a single packed bool is encoded as an unpacked one
+	single, err := Safe{}.MarshalBool(true, bs)
+	r.NoError(err)
+	r.Equal(AppendPacked(nil, PackBools(true), 1), bs[:single])
+	_, _, err = UnmarshalPacked([]byte{2}, 1)
+	r.ErrorIs(err, ErrInvalidPadding)
+
+	all := ^uint64(0)
+	b64 := AppendPacked(nil, all, MaxPacked)
+	got, _, err = UnmarshalPacked(b64, MaxPacked)
+	r.NoError(err)
+	r.Equal(all, got)
+}
+
+func TestReaderWriterPacked(t *testing.T) {
+	r := require.New(t)
+	var buf Buffer
+	w := NewWriter(Safe{}, &buf)
+	w.WritePacked(0b11, 2)
+	w.WriteUint8(9)
+	r.NoError(w.Err())
+	data := buf.Bytes
+	r.Equal([]byte{0b11, 9}, data)
+
+	rd := NewReader(Safe{}, data)
+	rd.Field("Flags")
+	r.Equal(uint64(0b11), rd.ReadPacked(2))
+	r.Equal(uint8(9), rd.ReadUint8())
+	r.NoError(rd.Err())
+
+	rd = NewReader(Safe{}, []byte{0b100})
+	rd.Field("Flags")
+	rd.ReadPacked(2)
+	var de *DecodeError
+	r.ErrorAs(rd.Err(), &de)
+	r.Equal("Flags", de.Field)
+	r.ErrorIs(de, ErrInvalidPadding)
+}
+
+type packedFlags struct {
+	Unsafe `gobin:"packed"`
+	A, B   bool
+	N      int16
+	c      bool
+	Inner  struct {
+		X, Y bool
+	}
+}
+
+func TestReflectPacked(t *testing.T) {
+	r := require.New(t)
+	v := packedFlags{B: true, N: -2, c: true}
+	v.Inner.Y = true
+	data, err := Marshal(&v)
+	r.NoError(err)
+	// nested structs are not packed
+	r.Equal([]byte{0b10, 0xfe, 0xff, 0b1, 0, 1}, data)
+
+	var got packedFlags
+	r.NoError(Unmarshal(data, &got))
+	r.Equal(v, got)
+
+	data[0] = 0b100
+	err = Unmarshal(data, &got)
+	r.ErrorIs(err, ErrInvalidPadding)
+	var de *DecodeError
+	r.ErrorAs(err, &de)
+	r.Equal("A", de.Field)
+}
@@ -0,0 +1,552 @@
+package gobin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DecodeError describes a failure to decode a value, wrapping one of the
+// sentinel errors such as ErrNotEnoughSpace.
+type DecodeError struct {
+	Offset int    // offset of the value in the decoded data
+	Field  string // dotted field path, e.g. Datas.Block[3].Videos[0].Vid
+	Type   string // expected type, e.g. uint32
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("gobin: decoding")
+	if e.Field != "" {
+		sb.WriteString(" ")
+		sb.WriteString(e.Field)
+	}
+	if e.Type != "" {
+		sb.WriteString(" (")
+		sb.WriteString(e.Type)
+		sb.WriteString(")")
+	}
+	fmt.Fprintf(&sb, " at offset %d: %v", e.Offset, e.Err)
+	return sb.String()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Reader decodes consecutive values from a byte slice. It keeps the offset of
+// the next value and the first error, so that a sequence of reads only needs
+// to be checked once at the end:
+//
+//	r := gobin.NewReader(gobin.Safe{}, data)
+//	o.ID = r.ReadUint32()
+//	o.Name = r.ReadString()
+//	if err := r.Err(); err != nil {
+//		return err
+//	}
+//
+// After an error every read returns the zero value and the offset no longer
+// moves.
+type Reader struct {
+	u       Unmarshaler
+	data    []byte
+	off     int
+	err     error
+	opts    DecodeOptions
+	alloc   int
+	field   string
+	base    int // position of the first index of field in index
+	index   []int
+	parents []readerFrame
+}
+
+// readerFrame is the field path of a type enclosing the one being decoded by
+// ReadValue.
+type readerFrame struct {
+	field string
+	base  int
+}
+
+// NewReader returns a Reader decoding data with u, limited by
+// DefaultDecodeOptions.
+func NewReader(u Unmarshaler, data []byte) *Reader {
+	return &Reader{u: u, data: data, opts: DefaultDecodeOptions}
+}
+
+// NewReaderOptions returns a Reader decoding data with u, limited by opts.
+func NewReaderOptions(u Unmarshaler, data []byte, opts DecodeOptions) *Reader {
+	return &Reader{u: u, data: data, opts: opts}
+}
+
+// Offset returns the number of bytes read so far. After an error it is the
+// offset of the value that failed.
+func (r *Reader) Offset() int {
+	return r.off
+}
+
+// Err returns the first error encountered, as a *DecodeError.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Field sets the field path reported in a DecodeError for the values read
+// next. Every %d in path is replaced by the index set with Index for that
+// nesting depth, e.g. "Block[%d].Videos[%d].Vid".
+//
+// The path is only formatted if decoding fails, so setting it is cheap.
+func (r *Reader) Field(path string) {
+	r.field = path
+}
+
+// Index sets the index of the depth-th %d in the field path.
+func (r *Reader) Index(depth, i int) {
+	depth += r.base
+	for len(r.index) <= depth {
+		r.index = append(r.index, 0)
+	}
+	r.index[depth] = i
+}
+
+func (r *Reader) path() string {
+	var sb strings.Builder
+	for _, f := range r.parents {
+		r.writeField(&sb, f.field, f.base)
+	}
+	r.writeField(&sb, r.field, r.base)
+	return sb.String()
+}
+
+func (r *Reader) writeField(sb *strings.Builder, field string, depth int) {
+	if field == "" {
+		return
+	}
+	if sb.Len() > 0 {
+		sb.WriteString(".")
+	}
+	for {
+		i := strings.Index(field, "%d")
+		if i < 0 {
+			break
+		}
+		sb.WriteString(field[:i])
+		if depth < len(r.index) {
+			fmt.Fprint(sb, r.index[depth])
+		} else {
+			sb.WriteString("?")
+		}
+		depth++
+		field = field[i+2:]
+	}
+	sb.WriteString(field)
+}
+
+// ReadValue decodes a nested type with r, so that it shares the limits and
+// the field path of the enclosing one.
+func (r *Reader) ReadValue(v ReaderUnmarshaler) {
+	if !r.enter() {
+		return
+	}
+	v.UnmarshalReader(r)
+	r.leave()
+}
+
+// enter starts a nested type at the current field. It reports false, and
+// records an error if MaxDepth is exceeded, when the nested type must not be
+// decoded.
+func (r *Reader) enter() bool {
+	if r.err != nil {
+		return false
+	}
+	if max := r.opts.MaxDepth; max > 0 && len(r.parents) >= max {
+		r.fail("", &LimitError{Limit: "depth", Len: len(r.parents) + 1, Max: max})
+		return false
+	}
+	r.parents = append(r.parents, readerFrame{field: r.field, base: r.base})
+	r.base += strings.Count(r.field, "%d")
+	r.field = ""
+	return true
+}
+
+// leave ends the nested type started by enter.
+func (r *Reader) leave() {
+	f := r.parents[len(r.parents)-1]
+	r.parents = r.parents[:len(r.parents)-1]
+	r.field, r.base = f.field, f.base
+}
+
+// fail records err as the first error. A DecodeError returned by a nested
+// type is made relative to r.
+func (r *Reader) fail(typ string, err error) {
+	de := &DecodeError{Offset: r.off, Field: r.path(), Type: typ, Err: err}
+	var nested *DecodeError
+	if errors.As(err, &nested) {
+		de.Offset += nested.Offset
+		de.Type = nested.Type
+		de.Err = nested.Err
+		switch {
+		case de.Field == "":
+			de.Field = nested.Field
+		case nested.Field != "":
+			de.Field += "." + nested.Field
+		}
+	}
+	r.err = de
+}
+
+// ReadLen reads the length prefix of a slice or map. As each element takes
+// at least a byte, a length beyond the bytes left fails with
+// ErrNotEnoughSpace before anything is allocated for it; Marshal and
+// cmd/bingen reject slices and maps of elements encoded in no bytes.
+func (r *Reader) ReadLen() int {
+	if r.err != nil {
+		return 0
+	}
+	l, n, err := r.u.UnmarshalInt(r.data[r.off:])
+	if err == nil && l < 0 {
+		err = ErrNegativeLength
+	}
+	if max := r.opts.MaxCollectionLen; err == nil && max > 0 && l > max {
+		err = &LimitError{Limit: "collection length", Len: l, Max: max}
+	}
+	if err == nil && l > len(r.data)-r.off-n {
+		err = ErrNotEnoughSpace
+	}
+	if err != nil {
+		r.fail("length", err)
+		return 0
+	}
+	r.off += n
+	return l
+}
+
+// ReadFunc decodes a value with fn, e.g. the UnmarshalFrom method of a nested
+// type.
+func (r *Reader) ReadFunc(fn func([]byte) (int, error)) {
+	if r.err != nil {
+		return
+	}
+	n, err := fn(r.data[r.off:])
+	if err != nil {
+		r.fail("", err)
+		return
+	}
+	r.off += n
+}
+
+// ReadBitFields reads a run of bit fields with fn, from the current offset
+// to the end of the last byte fn reads from.
+func (r *Reader) ReadBitFields(fn func(*BitReader)) {
+	if r.err != nil {
+		return
+	}
+	br := NewBitReader(r.data[r.off:])
+	fn(br)
+	n, err := br.Finish()
+	if err != nil {
+		r.fail("bits", err)
+		return
+	}
+	r.off += n
+}
+
+// ReadPacked reads n packed bools, as encoded by MarshalPacked.
+func (r *Reader) ReadPacked(n int) (bits uint64) {
+	if r.err != nil {
+		return
+	}
+	bits, m, err := UnmarshalPacked(r.data[r.off:], n)
+	if err != nil {
+		r.fail("packed bools", err)
+		return
+	}
+	r.off += m
+	return bits
+}
+
+func (r *Reader) ReadBool() (v bool) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalBool(r.data[r.off:])
+	if err != nil {
+		r.fail("bool", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadInt() (v int) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalInt(r.data[r.off:])
+	if err != nil {
+		r.fail("int", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadInt8() (v int8) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalInt8(r.data[r.off:])
+	if err != nil {
+		r.fail("int8", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadInt16() (v int16) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalInt16(r.data[r.off:])
+	if err != nil {
+		r.fail("int16", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadInt32() (v int32) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalInt32(r.data[r.off:])
+	if err != nil {
+		r.fail("int32", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadInt64() (v int64) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalInt64(r.data[r.off:])
+	if err != nil {
+		r.fail("int64", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadUint() (v uint) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalUint(r.data[r.off:])
+	if err != nil {
+		r.fail("uint", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadUint8() (v uint8) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalUint8(r.data[r.off:])
+	if err != nil {
+		r.fail("uint8", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadUint16() (v uint16) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalUint16(r.data[r.off:])
+	if err != nil {
+		r.fail("uint16", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadUint32() (v uint32) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalUint32(r.data[r.off:])
+	if err != nil {
+		r.fail("uint32", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadUint64() (v uint64) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalUint64(r.data[r.off:])
+	if err != nil {
+		r.fail("uint64", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadFloat32() (v float32) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalFloat32(r.data[r.off:])
+	if err != nil {
+		r.fail("float32", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadFloat64() (v float64) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalFloat64(r.data[r.off:])
+	if err != nil {
+		r.fail("float64", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadTime() (v time.Time) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalTime(r.data[r.off:])
+	if err != nil {
+		r.fail("time.Time", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadDuration() (v time.Duration) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalDuration(r.data[r.off:])
+	if err != nil {
+		r.fail("time.Duration", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+func (r *Reader) ReadGUID() (v GUID) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalGUID(r.data[r.off:])
+	if err != nil {
+		r.fail("GUID", err)
+		return
+	}
+	r.off += n
+	return v
+}
+
+// ReadString reads a string, aliasing the data or not as the decode Mode
+// says. Its length is checked against MaxBytesLen and, if it is copied,
+// counted against MaxAlloc before the copy, so the copy is never longer than
+// the limits or the remaining data.
+func (r *Reader) ReadString() (v string) {
+	if r.err != nil {
+		return
+	}
+	var (
+		n   int
+		err error
+	)
+	b, borrows := r.u.(StringBorrower)
+	if borrows {
+		v, n, err = b.BorrowString(r.data[r.off:])
+	} else {
+		v, n, err = r.u.UnmarshalString(r.data[r.off:])
+	}
+	if max := r.opts.MaxBytesLen; err == nil && max > 0 && len(v) > max {
+		v, err = "", &LimitError{Limit: "bytes length", Len: len(v), Max: max}
+	}
+	if err != nil {
+		r.fail("string", err)
+		return
+	}
+	if r.copiesString(borrows) {
+		if !r.allocate(len(v), 1) {
+			return ""
+		}
+		if borrows || r.opts.Mode == Own {
+			v = strings.Clone(v)
+		}
+	}
+	r.off += n
+	return v
+}
+
+// copiesString reports whether ReadString copies a string, and so counts it
+// against MaxAlloc: in the Own mode, and in CodecMode unless the codec's
+// UnmarshalString aliases the data, as that of Unsafe does. A codec that
+// does not borrow strings has decoded them with UnmarshalString already.
+func (r *Reader) copiesString(borrowed bool) bool {
+	switch r.opts.Mode {
+	case Own:
+		return true
+	case CodecMode:
+		_, aliases := r.u.(stringAliaser)
+		return !borrowed || !aliases
+	}
+	return false
+}
+
+// ReadBytes reads a byte slice, aliasing the data unless the decode Mode is
+// Own. Its length is checked against MaxBytesLen, and copies are counted
+// against MaxAlloc.
+func (r *Reader) ReadBytes() (v []byte) {
+	if r.err != nil {
+		return
+	}
+	v, n, err := r.u.UnmarshalBytes(r.data[r.off:])
+	if max := r.opts.MaxBytesLen; err == nil && max > 0 && len(v) > max {
+		v, err = nil, &LimitError{Limit: "bytes length", Len: len(v), Max: max}
+	}
+	if err != nil {
+		r.fail("[]byte", err)
+		return
+	}
+	if r.opts.Mode == Own {
+		if !r.allocate(len(v), 1) {
+			return nil
+		}
+		v = bytes.Clone(v)
+	}
+	r.off += n
+	return v
+}
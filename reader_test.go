@@ -0,0 +1,139 @@
+package gobin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader(t *testing.T) {
+	r := require.New(t)
+	t.Run("round trip", func(t *testing.T) {
+		for _, c := range []interface {
+			Marshaler
+			Unmarshaler
+		}{Safe{}, Unsafe{}, BigEndian{}, Varint{}} {
+			var buf Buffer
+			w := NewWriter(c, &buf)
+			w.WriteBool(true)
+			w.WriteInt(-7)
+			w.WriteUint32(42)
+			w.WriteFloat64(1.5)
+			w.WriteString("hello")
+			w.WriteBytes([]byte("world"))
+			w.WriteLen(3)
+			w.WriteUint8(1)
+			w.WriteUint8(2)
+			w.WriteUint8(3)
+			r.NoError(w.Err())
+
+			rd := NewReader(c, buf.Bytes)
+			r.True(rd.ReadBool())
+			r.Equal(-7, rd.ReadInt())
+			r.Equal(uint32(42), rd.ReadUint32())
+			r.Equal(1.5, rd.ReadFloat64())
+			r.Equal("hello", rd.ReadString())
+			r.Equal([]byte("world"), rd.ReadBytes())
+			r.Equal(3, rd.ReadLen())
+			r.Equal(uint8(1), rd.ReadUint8())
+			r.Equal(uint8(2), rd.ReadUint8())
+			r.Equal(uint8(3), rd.ReadUint8())
+			r.NoError(rd.Err())
+			r.Equal(len(buf.Bytes), rd.Offset())
+		}
+	})
+	t.Run("should keep the first error and its offset", func(t *testing.T) {
+		v := Safe{}
+		bs := make([]byte, 6)
+		_, err := v.MarshalUint32(1, bs)
+		r.NoError(err)
+		rd := NewReader(v, bs)
+		r.Equal(uint32(1), rd.ReadUint32())
+		r.Equal(uint64(0), rd.ReadUint64())
+		r.ErrorIs(rd.Err(), ErrNotEnoughSpace)
+		r.Equal(4, rd.Offset())
+		r.Equal(uint8(0), rd.ReadUint8())
+		r.ErrorIs(rd.Err(), ErrNotEnoughSpace)
+		r.Equal(4, rd.Offset())
+	})
+	t.Run("should return a DecodeError with the field path", func(t *testing.T) {
+		v := Safe{}
+		bs := make([]byte, 20)
+		_, err := v.MarshalInt(2, bs)
+		r.NoError(err)
+		rd := NewReader(v, bs)
+		rd.Field("Block")
+		l := rd.ReadLen()
+		for i := 0; i < l; i++ {
+			rd.Index(0, i)
+			rd.Field("Block[%d].ID")
+			rd.ReadUint64()
+		}
+		var de *DecodeError
+		r.ErrorAs(rd.Err(), &de)
+		r.ErrorIs(rd.Err(), ErrNotEnoughSpace)
+		r.Equal(16, de.Offset)
+		r.Equal("Block[1].ID", de.Field)
+		r.Equal("uint64", de.Type)
+		r.Equal("gobin: decoding Block[1].ID (uint64) at offset 16: not enough space", de.Error())
+	})
+	t.Run("should make a nested DecodeError relative", func(t *testing.T) {
+		v := Safe{}
+		bs := make([]byte, 8)
+		rd := NewReader(v, bs)
+		rd.ReadUint32()
+		rd.Field("Inner")
+		rd.ReadFunc(func(data []byte) (int, error) {
+			inner := NewReader(v, data)
+			inner.ReadUint16()
+			inner.Field("Name")
+			inner.ReadString()
+			return inner.Offset(), inner.Err()
+		})
+		var de *DecodeError
+		r.ErrorAs(rd.Err(), &de)
+		r.ErrorIs(rd.Err(), ErrNotEnoughSpace)
+		r.Equal(6, de.Offset)
+		r.Equal("Inner.Name", de.Field)
+		r.Equal("string", de.Type)
+	})
+	t.Run("should return ErrNegativeLength on a negative length", func(t *testing.T) {
+		v := Safe{}
+		bs := make([]byte, 8)
+		_, err := v.MarshalInt(-1, bs)
+		r.NoError(err)
+		rd := NewReader(v, bs)
+		r.Equal(0, rd.ReadLen())
+		r.ErrorIs(rd.Err(), ErrNegativeLength)
+	})
+	t.Run("should return ErrNotEnoughSpace on a length beyond the data", func(t *testing.T) {
+		v := Safe{}
+		bs := make([]byte, 8+3)
+		_, err := v.MarshalInt(4, bs)
+		r.NoError(err)
+		rd := NewReader(v, bs)
+		r.Equal(0, rd.ReadLen())
+		r.ErrorIs(rd.Err(), ErrNotEnoughSpace)
+		_, err = v.MarshalInt(3, bs)
+		r.NoError(err)
+		rd = NewReader(v, bs)
+		r.Equal(3, rd.ReadLen())
+		r.NoError(rd.Err())
+	})
+	t.Run("nested", func(t *testing.T) {
+		in := streamValue{ID: 5, Name: "nested"}
+		var buf Buffer
+		w := NewWriter(Safe{}, &buf)
+		w.WriteUint8(1)
+		w.WriteFunc(in.SizeBinary(), in.MarshalTo)
+		r.NoError(w.Err())
+		r.Equal(1+in.SizeBinary(), w.Offset())
+
+		var out streamValue
+		rd := NewReader(Safe{}, buf.Bytes)
+		r.Equal(uint8(1), rd.ReadUint8())
+		rd.ReadFunc(out.UnmarshalFrom)
+		r.NoError(rd.Err())
+		r.Equal(in, out)
+	})
+}
@@ -0,0 +1,711 @@
+package gobin
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Marshal returns the encoding of v, which must be a struct or a pointer to
+// one. The bytes are the same as those of the MarshalBinary method that
+// cmd/bingen generates for the struct: fields are encoded in order with the
+// codec the struct embeds, gobin.Safe if it embeds none.
+//
+// Nested structs are encoded inline, slices and maps are prefixed with their
+// length, arrays are not, and pointers are prefixed with a bool set when they
+// are nil. []byte is encoded as bytes, time.Time as a time and GUID, or any
+// [16]byte type named like one, as a GUID. Embedded fields are skipped. If
+// the embedded codec is tagged gobin:"packed", runs of consecutive bool
+// fields of v, not of its nested structs, are packed as by PackBools. Runs of
+// integer fields of v tagged gobin:"bits=N" are written as bit fields, see
+// BitWriter; Marshal fails if a value overflows its width, and a tag on
+// another type or wider than the field makes the type unsupported.
+// Interfaces, channels, functions, complex numbers and uintptr are not
+// supported, nor are slices of, and maps of keys and values of, types
+// encoded in no bytes, such as struct{}.
+func Marshal(v any) ([]byte, error) {
+	return marshal(v, false)
+}
+
+// MarshalCanonical returns the canonical encoding of v: that of Marshal,
+// with map entries written in the order of their encoded keys, floats
+// normalised by CanonicalFloat64 and empty byte slices written as nil. Equal
+// values have identical canonical encodings. The methods cmd/bingen generates
+// for a type marked gobin:canonical encode it the same way.
+func MarshalCanonical(v any) ([]byte, error) {
+	return marshal(v, true)
+}
+
+func marshal(v any, canonical bool) (data []byte, err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, &UnsupportedTypeError{Type: rv.Type()}
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, &UnsupportedTypeError{}
+	}
+	p, err := planOf(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			be, ok := r.(bitsError)
+			if !ok {
+				panic(r)
+			}
+			data, err = nil, be.err
+		}
+	}()
+	if canonical {
+		return p.canonical(p.codec, nil, rv), nil
+	}
+	return p.enc(p.codec, nil, rv), nil
+}
+
+// bitsError carries the overflow of a bit field out of the encoders, which
+// have no error result, to marshal.
+type bitsError struct {
+	err error
+}
+
+// Unmarshal decodes data, as encoded by Marshal, into the struct v points to.
+// Decoding failures are reported as a *DecodeError, limited by
+// DefaultDecodeOptions. Bytes following the struct are ignored.
+func Unmarshal(data []byte, v any) error {
+	return UnmarshalOptions(data, v, DefaultDecodeOptions)
+}
+
+// UnmarshalOptions is like Unmarshal, limited by opts.
+func UnmarshalOptions(data []byte, v any, opts DecodeOptions) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	rv = rv.Elem()
+	p, err := planOf(rv.Type())
+	if err != nil {
+		return err
+	}
+	r := NewReaderOptions(p.codec, data, opts)
+	p.dec(r, rv)
+	return r.Err()
+}
+
+// UnsupportedTypeError is returned by Marshal and Unmarshal for a type that
+// has no encoding.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Type == nil {
+		return "gobin: unsupported type: nil"
+	}
+	return "gobin: unsupported type: " + e.Type.String()
+}
+
+// InvalidUnmarshalError is returned by Unmarshal when v is not a non-nil
+// pointer.
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "gobin: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Pointer {
+		return "gobin: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "gobin: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// reflectCodec is a codec usable by both Marshal and Unmarshal.
+type reflectCodec interface {
+	Appender
+	Unmarshaler
+}
+
+type (
+	// encFunc appends the encoding of v to dst.
+	encFunc func(c Appender, dst []byte, v reflect.Value) []byte
+	// decFunc decodes r into v, which is settable.
+	decFunc func(r *Reader, v reflect.Value)
+)
+
+// plan is the encoders and decoder of a type, built once by planOf.
+type plan struct {
+	codec     reflectCodec
+	enc       encFunc
+	canonical encFunc
+	dec       decFunc
+}
+
+// plans caches the plan of every type passed to Marshal or Unmarshal.
+var plans sync.Map // map[reflect.Type]*plan
+
+func planOf(t reflect.Type) (*plan, error) {
+	if p, ok := plans.Load(t); ok {
+		return p.(*plan), nil
+	}
+	codec, packed := embeddedCodecOf(t)
+	b := &planBuilder{structs: make(map[reflect.Type]*structPlan), top: t, packed: packed}
+	cb := &planBuilder{structs: make(map[reflect.Type]*structPlan), top: t, packed: packed, canonical: true}
+	p := &plan{
+		codec:     codec,
+		enc:       b.encoder(t),
+		canonical: cb.encoder(t),
+		dec:       b.decoder(t, ""),
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	actual, _ := plans.LoadOrStore(t, p)
+	return actual.(*plan), nil
+}
+
+// embeddedCodecOf returns the codec embedded by struct type t, as cmd/bingen
+// picks it, or Safe, and whether it is tagged gobin:"packed".
+func embeddedCodecOf(t reflect.Type) (reflectCodec, bool) {
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.Anonymous || f.Type.Kind() == reflect.Pointer {
+				continue
+			}
+			if c, ok := reflect.Zero(f.Type).Interface().(reflectCodec); ok {
+				return c, f.Tag.Get("gobin") == "packed"
+			}
+		}
+	}
+	return Safe{}, false
+}
+
+// structPlan holds the fields of a struct type. It is registered before its
+// fields are built so that recursive types refer to it.
+type structPlan struct {
+	fields []fieldPlan
+}
+
+// fieldPlan is a field, or a run of packed bool fields or bit fields from
+// the one at index on, listed by bools or bits. enc and dec are then unused.
+type fieldPlan struct {
+	index int
+	enc   encFunc
+	dec   decFunc
+	bools []int
+	bits  []bitField
+}
+
+type bitField struct {
+	index  int
+	name   string
+	width  int
+	signed bool
+}
+
+type planBuilder struct {
+	structs map[reflect.Type]*structPlan
+	// canonical builds the encoders of MarshalCanonical, structs then have
+	// no decoders.
+	canonical bool
+	// top is the type passed to Marshal or Unmarshal, whose bools are
+	// packed if packed is set and whose fields may be bit fields.
+	top    reflect.Type
+	packed bool
+	err    error
+}
+
+func (b *planBuilder) unsupported(t reflect.Type) {
+	if b.err == nil {
+		b.err = &UnsupportedTypeError{Type: t}
+	}
+}
+
+func (b *planBuilder) structPlan(t reflect.Type) *structPlan {
+	if sp, ok := b.structs[t]; ok {
+		return sp
+	}
+	sp := &structPlan{}
+	b.structs[t] = sp
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			continue
+		}
+		if width := bitsTag(f.Tag.Get("gobin")); t == b.top && width != 0 {
+			widest, signed := bitsOf(f.Type)
+			if width < 0 || width > widest {
+				b.unsupported(f.Type)
+				continue
+			}
+			bf := bitField{index: i, name: f.Name, width: width, signed: signed}
+			if n := len(sp.fields); n > 0 && sp.fields[n-1].bits != nil {
+				sp.fields[n-1].bits = append(sp.fields[n-1].bits, bf)
+			} else {
+				sp.fields = append(sp.fields, fieldPlan{index: i, bits: []bitField{bf}})
+			}
+			continue
+		}
+		if t == b.top && b.packed && f.Type.Kind() == reflect.Bool {
+			if n := len(sp.fields); n > 0 && sp.fields[n-1].bools != nil && len(sp.fields[n-1].bools) < MaxPacked {
+				sp.fields[n-1].bools = append(sp.fields[n-1].bools, i)
+			} else {
+				sp.fields = append(sp.fields, fieldPlan{index: i, bools: []int{i}})
+			}
+			continue
+		}
+		fp := fieldPlan{index: i, enc: b.encoder(f.Type)}
+		if !b.canonical {
+			fp.dec = b.decoder(f.Type, f.Name)
+		}
+		sp.fields = append(sp.fields, fp)
+	}
+	return sp
+}
+
+// bitsOf returns the widest bit field of type t, 0 if t is not an integer,
+// and whether it is signed. int and uint are 64 bits wide on the wire.
+func bitsOf(t reflect.Type) (widest int, signed bool) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64:
+		return 64, true
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return t.Bits(), true
+	case reflect.Uint, reflect.Uint64:
+		return 64, false
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return t.Bits(), false
+	}
+	return 0, false
+}
+
+// encodesEmpty reports whether values of t are encoded in no bytes, as a
+// struct{} or a [0]int is. A slice or map of them is not supported, as
+// decoding could not check its length against the bytes left.
+func encodesEmpty(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array:
+		return t.Len() == 0 || encodesEmpty(t.Elem())
+	case reflect.Struct:
+		if t == timeType {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); !f.Anonymous && !encodesEmpty(f.Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	guidType = reflect.TypeOf(GUID{})
+)
+
+// exported returns v, a field of an addressable struct, usable even if the
+// field is unexported, as it is by the methods cmd/bingen generates.
+func exported(v reflect.Value) reflect.Value {
+	if v.CanSet() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+func (b *planBuilder) encoder(t reflect.Type) encFunc {
+	if t == timeType {
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendTime(dst, v.Interface().(time.Time))
+		}
+	}
+	if IsGUIDType(t) {
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendGUID(dst, v.Convert(guidType).Interface().(GUID))
+		}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendBool(dst, v.Bool())
+		}
+	case reflect.Int:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendInt(dst, int(v.Int()))
+		}
+	case reflect.Int8:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendInt8(dst, int8(v.Int()))
+		}
+	case reflect.Int16:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendInt16(dst, int16(v.Int()))
+		}
+	case reflect.Int32:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendInt32(dst, int32(v.Int()))
+		}
+	case reflect.Int64:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendInt64(dst, v.Int())
+		}
+	case reflect.Uint:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendUint(dst, uint(v.Uint()))
+		}
+	case reflect.Uint8:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendUint8(dst, uint8(v.Uint()))
+		}
+	case reflect.Uint16:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendUint16(dst, uint16(v.Uint()))
+		}
+	case reflect.Uint32:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendUint32(dst, uint32(v.Uint()))
+		}
+	case reflect.Uint64:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendUint64(dst, v.Uint())
+		}
+	case reflect.Float32:
+		if b.canonical {
+			return func(c Appender, dst []byte, v reflect.Value) []byte {
+				return c.AppendFloat32(dst, CanonicalFloat32(float32(v.Float())))
+			}
+		}
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendFloat32(dst, float32(v.Float()))
+		}
+	case reflect.Float64:
+		if b.canonical {
+			return func(c Appender, dst []byte, v reflect.Value) []byte {
+				return c.AppendFloat64(dst, CanonicalFloat64(v.Float()))
+			}
+		}
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendFloat64(dst, v.Float())
+		}
+	case reflect.String:
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			return c.AppendString(dst, v.String())
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && b.canonical {
+			return func(c Appender, dst []byte, v reflect.Value) []byte {
+				return c.AppendBytes(dst, CanonicalBytes(v.Bytes()))
+			}
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return func(c Appender, dst []byte, v reflect.Value) []byte {
+				return c.AppendBytes(dst, v.Bytes())
+			}
+		}
+		if encodesEmpty(t.Elem()) {
+			b.unsupported(t)
+		}
+		elem := b.encoder(t.Elem())
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			dst = c.AppendInt(dst, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				dst = elem(c, dst, v.Index(i))
+			}
+			return dst
+		}
+	case reflect.Array:
+		elem := b.encoder(t.Elem())
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			for i := 0; i < v.Len(); i++ {
+				dst = elem(c, dst, v.Index(i))
+			}
+			return dst
+		}
+	case reflect.Map:
+		if encodesEmpty(t.Key()) && encodesEmpty(t.Elem()) {
+			b.unsupported(t)
+		}
+		key, elem := b.encoder(t.Key()), b.encoder(t.Elem())
+		if b.canonical {
+			return func(c Appender, dst []byte, v reflect.Value) []byte {
+				dst = c.AppendInt(dst, v.Len())
+				var buf []byte
+				entries := make([]canonicalEntry[reflect.Value], 0, v.Len())
+				for it := v.MapRange(); it.Next(); {
+					start := len(buf)
+					buf = key(c, buf, it.Key())
+					entries = append(entries, canonicalEntry[reflect.Value]{buf[start:], it.Value()})
+				}
+				sortEntries(entries)
+				for _, e := range entries {
+					dst = append(dst, e.enc...)
+					dst = elem(c, dst, e.v)
+				}
+				return dst
+			}
+		}
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			dst = c.AppendInt(dst, v.Len())
+			for it := v.MapRange(); it.Next(); {
+				dst = key(c, dst, it.Key())
+				dst = elem(c, dst, it.Value())
+			}
+			return dst
+		}
+	case reflect.Pointer:
+		elem := b.encoder(t.Elem())
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			dst = c.AppendBool(dst, v.IsNil())
+			if !v.IsNil() {
+				dst = elem(c, dst, v.Elem())
+			}
+			return dst
+		}
+	case reflect.Struct:
+		sp := b.structPlan(t)
+		return func(c Appender, dst []byte, v reflect.Value) []byte {
+			if !v.CanAddr() {
+				// a map value or the argument of Marshal
+				cp := reflect.New(t).Elem()
+				cp.Set(v)
+				v = cp
+			}
+			for _, f := range sp.fields {
+				if f.bools != nil {
+					var bits uint64
+					for i, j := range f.bools {
+						if v.Field(j).Bool() {
+							bits |= 1 << i
+						}
+					}
+					dst = AppendPacked(dst, bits, len(f.bools))
+					continue
+				}
+				if f.bits != nil {
+					w := NewBitWriter(dst)
+					for _, bf := range f.bits {
+						var err error
+						if fv := v.Field(bf.index); bf.signed {
+							err = w.WriteSignedBits(fv.Int(), bf.width)
+						} else {
+							err = w.WriteBits(fv.Uint(), bf.width)
+						}
+						if err != nil {
+							panic(bitsError{fmt.Errorf("%s: %w", bf.name, err)})
+						}
+					}
+					dst = w.Bytes()
+					continue
+				}
+				dst = f.enc(c, dst, exported(v.Field(f.index)))
+			}
+			return dst
+		}
+	}
+	b.unsupported(t)
+	return nil
+}
+
+// decoder returns the decoder of t. path is the field path of the value
+// within the innermost struct, with a %d for every enclosing slice, array
+// or map, as in Reader.Field.
+func (b *planBuilder) decoder(t reflect.Type, path string) decFunc {
+	depth := strings.Count(path, "%d")
+	if t == timeType {
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.Set(reflect.ValueOf(r.ReadTime()))
+		}
+	}
+	if IsGUIDType(t) {
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.Set(reflect.ValueOf(r.ReadGUID()).Convert(t))
+		}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetBool(r.ReadBool())
+		}
+	case reflect.Int:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetInt(int64(r.ReadInt()))
+		}
+	case reflect.Int8:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetInt(int64(r.ReadInt8()))
+		}
+	case reflect.Int16:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetInt(int64(r.ReadInt16()))
+		}
+	case reflect.Int32:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetInt(int64(r.ReadInt32()))
+		}
+	case reflect.Int64:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetInt(r.ReadInt64())
+		}
+	case reflect.Uint:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetUint(uint64(r.ReadUint()))
+		}
+	case reflect.Uint8:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetUint(uint64(r.ReadUint8()))
+		}
+	case reflect.Uint16:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetUint(uint64(r.ReadUint16()))
+		}
+	case reflect.Uint32:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetUint(uint64(r.ReadUint32()))
+		}
+	case reflect.Uint64:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetUint(r.ReadUint64())
+		}
+	case reflect.Float32:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetFloat(float64(r.ReadFloat32()))
+		}
+	case reflect.Float64:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetFloat(r.ReadFloat64())
+		}
+	case reflect.String:
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			v.SetString(r.ReadString())
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return func(r *Reader, v reflect.Value) {
+				r.Field(path)
+				v.SetBytes(r.ReadBytes())
+			}
+		}
+		elem, size := b.decoder(t.Elem(), path+"[%d]"), int(t.Elem().Size())
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			l := r.ReadLen()
+			if !r.allocate(l, size) {
+				v.SetZero()
+				return
+			}
+			v.Set(reflect.MakeSlice(t, l, l))
+			for i := 0; i < l && r.err == nil; i++ {
+				r.Index(depth, i)
+				elem(r, v.Index(i))
+			}
+		}
+	case reflect.Array:
+		elem := b.decoder(t.Elem(), path+"[%d]")
+		return func(r *Reader, v reflect.Value) {
+			for i := 0; i < v.Len() && r.err == nil; i++ {
+				r.Index(depth, i)
+				elem(r, v.Index(i))
+			}
+		}
+	case reflect.Map:
+		key, elem := b.decoder(t.Key(), path+"[%d]"), b.decoder(t.Elem(), path+"[%d]")
+		size := int(t.Key().Size() + t.Elem().Size())
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			l := r.ReadLen()
+			if !r.allocate(l, size) {
+				v.SetZero()
+				return
+			}
+			m := reflect.MakeMapWithSize(t, l)
+			v.Set(m)
+			for i := 0; i < l && r.err == nil; i++ {
+				r.Index(depth, i)
+				k, e := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+				key(r, k)
+				elem(r, e)
+				m.SetMapIndex(k, e)
+			}
+		}
+	case reflect.Pointer:
+		elem := b.decoder(t.Elem(), path)
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			if isnil := r.ReadBool(); isnil || r.err != nil {
+				v.SetZero()
+				return
+			}
+			p := reflect.New(t.Elem())
+			v.Set(p)
+			elem(r, p.Elem())
+		}
+	case reflect.Struct:
+		sp := b.structPlan(t)
+		dec := func(r *Reader, v reflect.Value) {
+			for _, f := range sp.fields {
+				if f.bools != nil {
+					r.Field(t.Field(f.index).Name)
+					bits := r.ReadPacked(len(f.bools))
+					for i, j := range f.bools {
+						exported(v.Field(j)).SetBool(bits&(1<<i) != 0)
+					}
+					continue
+				}
+				if f.bits != nil {
+					r.Field(t.Field(f.index).Name)
+					r.ReadBitFields(func(br *BitReader) {
+						for _, bf := range f.bits {
+							if fv := exported(v.Field(bf.index)); bf.signed {
+								fv.SetInt(br.ReadSignedBits(bf.width))
+							} else {
+								fv.SetUint(br.ReadBits(bf.width))
+							}
+						}
+					})
+					continue
+				}
+				f.dec(r, exported(v.Field(f.index)))
+			}
+		}
+		if path == "" {
+			return dec
+		}
+		// a nested struct gets a Reader frame of its own, as with ReadValue,
+		// so that its field paths do not depend on where it is used.
+		return func(r *Reader, v reflect.Value) {
+			r.Field(path)
+			if !r.enter() {
+				return
+			}
+			dec(r, v)
+			r.leave()
+		}
+	}
+	b.unsupported(t)
+	return nil
+}
@@ -0,0 +1,127 @@
+package gobin
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type reflectInner struct {
+	X  uint32
+	Ys []string
+}
+
+type reflectValue struct {
+	Varint
+	A    int
+	name string
+	B    []byte
+	C    [2]int16
+	D    *reflectInner
+	E    []reflectInner
+	F    map[string]uint8
+	G    float64
+}
+
+type reflectNode struct {
+	Value int8
+	Next  *reflectNode
+}
+
+func TestReflect(t *testing.T) {
+	r := require.New(t)
+	v := &reflectValue{
+		A:    -1,
+		name: "n",
+		B:    []byte("b"),
+		C:    [2]int16{1, -2},
+		D:    &reflectInner{X: 3, Ys: []string{"y"}},
+		E:    []reflectInner{{X: 4, Ys: []string{}}},
+		F:    map[string]uint8{"f": 5},
+		G:    1.5,
+	}
+	t.Run("matches the generated encoding", func(t *testing.T) {
+		c := Varint{}
+		var want []byte
+		want = c.AppendInt(want, v.A)
+		want = c.AppendString(want, v.name)
+		want = c.AppendBytes(want, v.B)
+		want = c.AppendInt16(want, v.C[0])
+		want = c.AppendInt16(want, v.C[1])
+		want = c.AppendBool(want, false)
+		want = c.AppendUint32(want, v.D.X)
+		want = c.AppendInt(want, 1)
+		want = c.AppendString(want, "y")
+		want = c.AppendInt(want, 1)
+		want = c.AppendUint32(want, 4)
+		want = c.AppendInt(want, 0)
+		want = c.AppendInt(want, 1)
+		want = c.AppendString(want, "f")
+		want = c.AppendUint8(want, 5)
+		want = c.AppendFloat64(want, v.G)
+		bs, err := Marshal(v)
+		r.NoError(err)
+		r.Equal(want, bs)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		bs, err := Marshal(*v)
+		r.NoError(err)
+		var v2 reflectValue
+		r.NoError(Unmarshal(bs, &v2))
+		r.Equal(*v, v2)
+	})
+	t.Run("recursive type", func(t *testing.T) {
+		n := &reflectNode{Value: 1, Next: &reflectNode{Value: 2}}
+		bs, err := Marshal(n)
+		r.NoError(err)
+		r.Equal([]byte{1, 0, 2, 1}, bs)
+		var n2 reflectNode
+		r.NoError(Unmarshal(bs, &n2))
+		r.Equal(*n, n2)
+	})
+	t.Run("should return a DecodeError with the field path", func(t *testing.T) {
+		bs, err := Marshal(v)
+		r.NoError(err)
+		var v2 reflectValue
+		err = Unmarshal(bs[:len(bs)-13], &v2)
+		var de *DecodeError
+		r.ErrorAs(err, &de)
+		r.Equal("E[0].Ys", de.Field)
+		r.ErrorIs(err, ErrNotEnoughSpace)
+	})
+	t.Run("should cache plans", func(t *testing.T) {
+		p1, err := planOf(reflect.TypeOf(reflectValue{}))
+		r.NoError(err)
+		p2, err := planOf(reflect.TypeOf(reflectValue{}))
+		r.NoError(err)
+		r.Same(p1, p2)
+	})
+	t.Run("should reject unsupported types", func(t *testing.T) {
+		_, err := Marshal(struct{ F func() }{})
+		var ue *UnsupportedTypeError
+		r.ErrorAs(err, &ue)
+		r.Equal(reflect.TypeOf(func() {}), ue.Type)
+		for _, v := range []any{
+			&struct{ S []struct{} }{S: make([]struct{}, 3)},
+			&struct{ S [][0]int }{},
+			&struct{ M map[struct{}][0]int }{},
+		} {
+			_, err = Marshal(v)
+			r.ErrorAs(err, &ue, "%T", v)
+			r.ErrorAs(Unmarshal(nil, v), &ue, "%T", v)
+		}
+		type keyed struct {
+			M map[struct{}]int
+			A [0]int
+		}
+		data, err := Marshal(&keyed{M: map[struct{}]int{{}: 1}})
+		r.NoError(err)
+		var k keyed
+		r.NoError(Unmarshal(data, &k))
+		r.Equal(map[struct{}]int{{}: 1}, k.M)
+		var ie *InvalidUnmarshalError
+		r.ErrorAs(Unmarshal(nil, reflectValue{}), &ie)
+		r.ErrorAs(Unmarshal(nil, (*reflectValue)(nil)), &ie)
+	})
+}
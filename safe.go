@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
+	"time"
 )
 
 var _ Marshaler = Safe{}
+var _ Appender = Safe{}
 
 var (
 	marshalSafeInt    func(v int, bs []byte) (n int, err error)
@@ -15,23 +18,48 @@ var (
 	unmarshalSafeUint func(bs []byte) (v uint, n int, err error)
 )
 
+// int, uint and all length prefixes are encoded as 64-bit values on every
+// platform. On hosts where int is 32 bits wide, decoding a value that does not
+// fit returns ErrOverflow.
 func init() {
+	marshalSafeInt = marshalSafeInteger64[int]
+	marshalSafeUint = marshalSafeInteger64[uint]
 	switch strconv.IntSize {
 	case 32:
-		marshalSafeInt = marshalSafeInteger32[int]
-		unmarshalSafeInt = unmarshalSafeInteger32[int]
-		marshalSafeUint = marshalSafeInteger32[uint]
-		unmarshalSafeUint = unmarshalSafeInteger32[uint]
+		unmarshalSafeInt = unmarshalSafeNarrowInt
+		unmarshalSafeUint = unmarshalSafeNarrowUint
 	case 64:
-		marshalSafeInt = marshalSafeInteger64[int]
 		unmarshalSafeInt = unmarshalSafeInteger64[int]
-		marshalSafeUint = marshalSafeInteger64[uint]
 		unmarshalSafeUint = unmarshalSafeInteger64[uint]
 	default:
 		panic("unsupported int size")
 	}
 }
 
+// unmarshalSafeNarrowInt decodes a 64-bit int into a 32-bit int.
+func unmarshalSafeNarrowInt(bs []byte) (v int, n int, err error) {
+	i, n, err := unmarshalSafeInteger64[int64](bs)
+	if err != nil {
+		return
+	}
+	if i < math.MinInt32 || i > math.MaxInt32 {
+		return 0, 0, ErrOverflow
+	}
+	return int(i), n, nil
+}
+
+// unmarshalSafeNarrowUint decodes a 64-bit uint into a 32-bit uint.
+func unmarshalSafeNarrowUint(bs []byte) (v uint, n int, err error) {
+	i, n, err := unmarshalSafeInteger64[uint64](bs)
+	if err != nil {
+		return
+	}
+	if i > math.MaxUint32 {
+		return 0, 0, ErrOverflow
+	}
+	return uint(i), n, nil
+}
+
 func marshalSafeInteger8[T Integer8](t T, bs []byte) (n int, err error) {
 	if len(bs) < 1 {
 		return 0, ErrNotEnoughSpace
@@ -144,6 +172,25 @@ func unmarshalBool(bs []byte) (v bool, n int, err error) {
 	return v, 1, err
 }
 
+func appendSafeInteger16[T Integer16](dst []byte, t T) []byte {
+	return append(dst, byte(t), byte(t>>8))
+}
+
+func appendSafeInteger32[T Integer32](dst []byte, t T) []byte {
+	return append(dst, byte(t), byte(t>>8), byte(t>>16), byte(t>>24))
+}
+
+func appendSafeInteger64[T Integer64](dst []byte, t T) []byte {
+	return append(dst, byte(t), byte(t>>8), byte(t>>16), byte(t>>24), byte(t>>32), byte(t>>40), byte(t>>48), byte(t>>56))
+}
+
+func appendBool(dst []byte, v bool) []byte {
+	if v {
+		return append(dst, 1)
+	}
+	return append(dst, 0)
+}
+
 type Safe struct{}
 
 func (Safe) MarshalBool(v bool, bs []byte) (n int, err error) {
@@ -244,7 +291,14 @@ func (Safe) MarshalString(v string, bs []byte) (n int, err error) {
 	return n + copy(bs[n:], v), nil
 }
 
-func (Safe) UnmarshalString(bs []byte) (v string, n int, err error) {
+// UnmarshalString decodes a copy of the string at the start of bs.
+func (c Safe) UnmarshalString(bs []byte) (v string, n int, err error) {
+	v, n, err = c.BorrowString(bs)
+	return strings.Clone(v), n, err
+}
+
+// BorrowString decodes the string at the start of bs, aliasing bs.
+func (Safe) BorrowString(bs []byte) (v string, n int, err error) {
 	l, n, err := unmarshalSafeInt(bs)
 	if err != nil {
 		return
@@ -257,7 +311,7 @@ func (Safe) UnmarshalString(bs []byte) (v string, n int, err error) {
 		err = ErrNotEnoughSpace
 		return
 	}
-	return string(bs[n : n+l]), n + l, nil
+	return aliasString(bs[n : n+l]), n + l, nil
 }
 
 func (Safe) MarshalByte(v byte, bs []byte) (n int, err error) {
@@ -273,19 +327,14 @@ func (Safe) MarshalBytes(v []byte, bs []byte) (n int, err error) {
 	if v == nil {
 		return marshalBool(true, bs)
 	}
-	n, err = marshalBool(false, bs)
-	if err != nil {
+	if _, err = marshalBool(false, bs); err != nil {
 		return
 	}
-	n, err = marshalSafeInt(len(v), bs[n:])
+	n, err = marshalSafeInt(len(v), bs[1:])
 	if err != nil {
 		return
 	}
 	n += 1
-	//case: v = []byte{""}
-	if n == 1 {
-		return
-	}
 	if len(bs[n:]) < len(v) {
 		return 0, ErrNotEnoughSpace
 	}
@@ -342,6 +391,117 @@ func (Safe) UnmarshalFloat64(bs []byte) (v float64, n int, err error) {
 	return math.Float64frombits(uv), n, nil
 }
 
+func (Safe) AppendBool(dst []byte, v bool) []byte {
+	return appendBool(dst, v)
+}
+
+func (Safe) AppendInt(dst []byte, v int) []byte {
+	return appendSafeInteger64(dst, int64(v))
+}
+
+func (Safe) AppendInt8(dst []byte, v int8) []byte {
+	return append(dst, byte(v))
+}
+
+func (Safe) AppendInt16(dst []byte, v int16) []byte {
+	return appendSafeInteger16(dst, v)
+}
+
+func (Safe) AppendInt32(dst []byte, v int32) []byte {
+	return appendSafeInteger32(dst, v)
+}
+
+func (Safe) AppendInt64(dst []byte, v int64) []byte {
+	return appendSafeInteger64(dst, v)
+}
+
+func (Safe) AppendUint(dst []byte, v uint) []byte {
+	return appendSafeInteger64(dst, uint64(v))
+}
+
+func (Safe) AppendUint8(dst []byte, v uint8) []byte {
+	return append(dst, byte(v))
+}
+
+func (Safe) AppendUint16(dst []byte, v uint16) []byte {
+	return appendSafeInteger16(dst, v)
+}
+
+func (Safe) AppendUint32(dst []byte, v uint32) []byte {
+	return appendSafeInteger32(dst, v)
+}
+
+func (Safe) AppendUint64(dst []byte, v uint64) []byte {
+	return appendSafeInteger64(dst, v)
+}
+
+func (Safe) AppendFloat32(dst []byte, v float32) []byte {
+	return appendSafeInteger32(dst, math.Float32bits(v))
+}
+
+func (Safe) AppendFloat64(dst []byte, v float64) []byte {
+	return appendSafeInteger64(dst, math.Float64bits(v))
+}
+
+func (Safe) AppendString(dst []byte, v string) []byte {
+	dst = appendSafeInteger64(dst, int64(len(v)))
+	return append(dst, v...)
+}
+
+func (Safe) AppendByte(dst []byte, v byte) []byte {
+	return append(dst, byte(v))
+}
+
+func (c Safe) AppendBytes(dst []byte, v []byte) []byte {
+	return append(c.appendBytesPrefix(dst, v), v...)
+}
+
+// appendBytesPrefix appends the isnil flag and length preceding v.
+func (Safe) appendBytesPrefix(dst []byte, v []byte) []byte {
+	if v == nil {
+		return appendBool(dst, true)
+	}
+	dst = appendBool(dst, false)
+	return appendSafeInteger64(dst, int64(len(v)))
+}
+
+func (c Safe) MarshalTime(v time.Time, bs []byte) (n int, err error) {
+	return marshalTime(c, v, bs)
+}
+
+func (c Safe) UnmarshalTime(bs []byte) (v time.Time, n int, err error) {
+	return unmarshalTime(c, bs)
+}
+
+func (c Safe) AppendTime(dst []byte, v time.Time) []byte {
+	return appendTime(c, dst, v)
+}
+
+func (c Safe) MarshalDuration(v time.Duration, bs []byte) (n int, err error) {
+	return c.MarshalInt64(int64(v), bs)
+}
+
+func (c Safe) UnmarshalDuration(bs []byte) (v time.Duration, n int, err error) {
+	i, n, err := c.UnmarshalInt64(bs)
+	return time.Duration(i), n, err
+}
+
+func (c Safe) AppendDuration(dst []byte, v time.Duration) []byte {
+	return c.AppendInt64(dst, int64(v))
+}
+
+func (Safe) MarshalGUID(v GUID, bs []byte) (n int, err error) {
+	return marshalGUID(v, bs)
+}
+
+func (Safe) UnmarshalGUID(bs []byte) (v GUID, n int, err error) {
+	return unmarshalGUID(bs)
+}
+
+func (Safe) AppendGUID(dst []byte, v GUID) []byte {
+	return appendGUID(dst, v)
+}
+
 func (Safe) MarshalBinary() ([]byte, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
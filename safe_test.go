@@ -238,10 +238,52 @@ func TestSafe(t *testing.T) {
 		bs2 := make([]byte, 100)
 		n, err := v.MarshalBytes(bs, bs2)
 		r.NoError(err)
-		r.Equal(19, n)
+		r.Equal(20, n)
 		bs3, n, err := v.UnmarshalBytes(bs2)
 		r.NoError(err)
-		r.Equal(19, n)
+		r.Equal(20, n)
 		r.Equal(bs, bs3)
 	})
 }
+
+func TestSafeIntWidth(t *testing.T) {
+	r := require.New(t)
+	v := Safe{}
+	defer func(u func([]byte) (int, int, error), uu func([]byte) (uint, int, error)) {
+		unmarshalSafeInt, unmarshalSafeUint = u, uu
+	}(unmarshalSafeInt, unmarshalSafeUint)
+	// decode as a host with a 32-bit int would
+	unmarshalSafeInt, unmarshalSafeUint = unmarshalSafeNarrowInt, unmarshalSafeNarrowUint
+
+	t.Run("int is always 8 bytes", func(t *testing.T) {
+		bs := make([]byte, 8)
+		n, err := v.MarshalInt(-2, bs)
+		r.NoError(err)
+		r.Equal(8, n)
+		r.Equal([]byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, bs)
+		i, n, err := v.UnmarshalInt(bs)
+		r.NoError(err)
+		r.Equal(8, n)
+		r.Equal(-2, i)
+	})
+	t.Run("int should return ErrOverflow if it does not fit", func(t *testing.T) {
+		bs := make([]byte, 8)
+		_, err := v.MarshalInt64(math.MaxInt32+1, bs)
+		r.NoError(err)
+		_, n, err := v.UnmarshalInt(bs)
+		r.ErrorIs(err, ErrOverflow)
+		r.Equal(0, n)
+		_, err = v.MarshalUint64(math.MaxUint32+1, bs)
+		r.NoError(err)
+		_, n, err = v.UnmarshalUint(bs)
+		r.ErrorIs(err, ErrOverflow)
+		r.Equal(0, n)
+	})
+	t.Run("string length should return ErrOverflow if it does not fit", func(t *testing.T) {
+		bs := make([]byte, 16)
+		_, err := v.MarshalInt64(math.MaxInt64, bs)
+		r.NoError(err)
+		_, _, err = v.UnmarshalString(bs)
+		r.ErrorIs(err, ErrOverflow)
+	})
+}
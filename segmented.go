@@ -0,0 +1,172 @@
+package gobin
+
+import (
+	"io"
+	"net"
+)
+
+const (
+	// segmentChunkSize is the size of the pooled chunks of a
+	// SegmentedBuffer, one of the buffer pool size classes.
+	segmentChunkSize = 64 << 10
+
+	// SegmentRefThreshold is the length from which a Writer on a
+	// SegmentedBuffer references []byte values instead of copying them.
+	SegmentRefThreshold = 16 << 10
+)
+
+// SegmentedBuffer is a chain of pooled chunks, for payloads too large to
+// grow a Buffer for: writes never copy what was written before. Slices added
+// with WriteRef are referenced rather than copied, and WriteTo sends all of
+// it with net.Buffers, a single writev on a network connection.
+//
+// Encode into it with NewSegmentedWriter, and call Reset once it is sent to
+// return its chunks to the pool.
+type SegmentedBuffer struct {
+	segs   net.Buffers // sealed segments
+	sealed int         // length of segs
+	chunks []*Buffer   // pooled chunks, the last one being written
+	start  int         // start of the unsealed bytes of the last chunk
+}
+
+var (
+	_ io.Writer     = &SegmentedBuffer{}
+	_ io.ReaderFrom = &SegmentedBuffer{}
+	_ io.WriterTo   = &SegmentedBuffer{}
+)
+
+// cur returns the chunk being written, or nil.
+func (b *SegmentedBuffer) cur() *Buffer {
+	if len(b.chunks) == 0 {
+		return nil
+	}
+	return b.chunks[len(b.chunks)-1]
+}
+
+// reserve returns the chunk being written, starting a new one unless it has
+// room for n more bytes.
+func (b *SegmentedBuffer) reserve(n int) *Buffer {
+	if c := b.cur(); c != nil && c.Cap()-c.Len() >= n {
+		return c
+	}
+	b.seal()
+	c := NewBufferFromPoolWithCap(max(n, segmentChunkSize))
+	b.chunks = append(b.chunks, c)
+	b.start = 0
+	return c
+}
+
+// seal adds the bytes written to the current chunk since the last seal to
+// the segments.
+func (b *SegmentedBuffer) seal() {
+	if c := b.cur(); c != nil && c.Len() > b.start {
+		b.segs = append(b.segs, c.Bytes[b.start:])
+		b.sealed += c.Len() - b.start
+		b.start = c.Len()
+	}
+}
+
+// Len returns the number of bytes in the buffer
+func (b *SegmentedBuffer) Len() int {
+	if c := b.cur(); c != nil {
+		return b.sealed + c.Len() - b.start
+	}
+	return b.sealed
+}
+
+// Write copies a chunk of bytes to the buffer
+func (b *SegmentedBuffer) Write(v []byte) (int, error) {
+	n := len(v)
+	for len(v) > 0 {
+		c := b.reserve(1)
+		m := copy(c.Bytes[c.Len():c.Cap()], v)
+		c.Bytes = c.Bytes[:c.Len()+m]
+		v = v[m:]
+	}
+	return n, nil
+}
+
+// WriteString writes a string to the buffer
+func (b *SegmentedBuffer) WriteString(v string) {
+	for len(v) > 0 {
+		c := b.reserve(1)
+		m := copy(c.Bytes[c.Len():c.Cap()], v)
+		c.Bytes = c.Bytes[:c.Len()+m]
+		v = v[m:]
+	}
+}
+
+// WriteByte writes a single byte into the buffer
+func (b *SegmentedBuffer) WriteByte(v byte) error {
+	c := b.reserve(1)
+	c.Bytes = append(c.Bytes, v)
+	return nil
+}
+
+// WriteRef adds v to the buffer without copying it. v must not be modified
+// until the buffer is sent or Reset.
+func (b *SegmentedBuffer) WriteRef(v []byte) {
+	if len(v) == 0 {
+		return
+	}
+	b.seal()
+	b.segs = append(b.segs, v)
+	b.sealed += len(v)
+}
+
+// Append appends the encoding of v to the buffer, with MarshalSegmented if v
+// is a SegmentedMarshaler, or else in a chunk with room for SizeBinary bytes
+// if v has the method, so that AppendBinary does not grow it past its pooled
+// capacity.
+func (b *SegmentedBuffer) Append(v BinaryAppender) (err error) {
+	if m, ok := v.(SegmentedMarshaler); ok {
+		return m.MarshalSegmented(b)
+	}
+	n := minRead
+	if s, ok := v.(interface{ SizeBinary() int }); ok {
+		n = s.SizeBinary()
+	}
+	c := b.reserve(n)
+	c.Bytes, err = v.AppendBinary(c.Bytes)
+	return
+}
+
+// ReadFrom reads from r until EOF, appending to the buffer
+func (b *SegmentedBuffer) ReadFrom(r io.Reader) (int64, error) {
+	var total int64
+	for {
+		c := b.reserve(minRead)
+		n, err := r.Read(c.Bytes[c.Len():c.Cap()])
+		if n < 0 {
+			panic("gobin: reader returned negative count from Read")
+		}
+		c.Bytes = c.Bytes[:c.Len()+n]
+		total += int64(n)
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// WriteTo writes the contents of the buffer to w with net.Buffers, which
+// uses writev when w is a network connection. The buffer is left as it is.
+func (b *SegmentedBuffer) WriteTo(w io.Writer) (int64, error) {
+	b.seal()
+	bufs := make(net.Buffers, len(b.segs))
+	copy(bufs, b.segs)
+	return bufs.WriteTo(w)
+}
+
+// Reset empties the buffer, returning its chunks to the pool and dropping
+// the slices it references.
+func (b *SegmentedBuffer) Reset() {
+	for _, c := range b.chunks {
+		c.ReturnToPool()
+	}
+	clear(b.segs)
+	clear(b.chunks)
+	*b = SegmentedBuffer{segs: b.segs[:0], chunks: b.chunks[:0]}
+}
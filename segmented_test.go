@@ -0,0 +1,155 @@
+package gobin
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmentedBuffer(t *testing.T) {
+	r := require.New(t)
+	t.Run("write across chunks", func(t *testing.T) {
+		var b SegmentedBuffer
+		defer b.Reset()
+		big := bytes.Repeat([]byte("0123456789"), segmentChunkSize/4)
+		b.WriteString("<")
+		_, err := b.Write(big)
+		r.NoError(err)
+		r.NoError(b.WriteByte('>'))
+		r.Equal(len(big)+2, b.Len())
+		r.Greater(len(b.chunks), 1)
+
+		var out bytes.Buffer
+		n, err := b.WriteTo(&out)
+		r.NoError(err)
+		r.Equal(int64(b.Len()), n)
+		r.Equal("<"+string(big)+">", out.String())
+	})
+	t.Run("references", func(t *testing.T) {
+		var b SegmentedBuffer
+		defer b.Reset()
+		ref := []byte("referenced")
+		b.WriteString("a")
+		b.WriteRef(ref)
+		b.WriteString("b")
+		r.Len(b.chunks, 1)
+		b.seal()
+		r.Len(b.segs, 3)
+		r.True(aliases(b.segs[1], unsafe.Pointer(&ref[0]), len(ref)))
+
+		ref[0] = 'R'
+		var out bytes.Buffer
+		_, err := b.WriteTo(&out)
+		r.NoError(err)
+		r.Equal("aReferencedb", out.String())
+	})
+	t.Run("read from", func(t *testing.T) {
+		var b SegmentedBuffer
+		defer b.Reset()
+		src := strings.Repeat("0123456789", segmentChunkSize/5)
+		n, err := b.ReadFrom(iotest.HalfReader(strings.NewReader(src)))
+		r.NoError(err)
+		r.Equal(int64(len(src)), n)
+		var out bytes.Buffer
+		_, err = b.WriteTo(&out)
+		r.NoError(err)
+		r.Equal(src, out.String())
+
+		_, err = b.ReadFrom(iotest.ErrReader(iotest.ErrTimeout))
+		r.ErrorIs(err, iotest.ErrTimeout)
+	})
+	t.Run("append", func(t *testing.T) {
+		var b SegmentedBuffer
+		defer b.Reset()
+		b.WriteString("x")
+		v := &sizedAppender{n: 2 * segmentChunkSize}
+		r.NoError(b.Append(v))
+		r.True(v.roomy)
+		r.Equal(1+v.n, b.Len())
+	})
+	t.Run("reset", func(t *testing.T) {
+		var b SegmentedBuffer
+		b.WriteString("abc")
+		b.WriteRef([]byte("def"))
+		b.Reset()
+		r.Equal(0, b.Len())
+		var out bytes.Buffer
+		_, err := b.WriteTo(&out)
+		r.NoError(err)
+		r.Zero(out.Len())
+	})
+	t.Run("writev", func(t *testing.T) {
+		var b SegmentedBuffer
+		defer b.Reset()
+		b.WriteString("head")
+		b.WriteRef(bytes.Repeat([]byte{1}, 1000))
+		b.WriteString("tail")
+
+		c1, c2 := net.Pipe()
+		done := make(chan []byte)
+		go func() {
+			var got bytes.Buffer
+			_, _ = got.ReadFrom(c2)
+			done <- got.Bytes()
+		}()
+		_, err := b.WriteTo(c1)
+		r.NoError(err)
+		r.NoError(c1.Close())
+		got := <-done
+		r.Len(got, b.Len())
+		r.Equal("head", string(got[:4]))
+		r.Equal("tail", string(got[len(got)-4:]))
+	})
+}
+
+func TestSegmentedWriter(t *testing.T) {
+	r := require.New(t)
+	big := bytes.Repeat([]byte{7}, SegmentRefThreshold)
+	for _, c := range []interface {
+		Marshaler
+		Appender
+	}{Safe{}, Unsafe{}, BigEndian{}, Varint{}} {
+		var b SegmentedBuffer
+		b.WriteString("x")
+		w := NewSegmentedWriter(c, &b)
+		w.WriteInt32(-1)
+		w.WriteBytes(big)
+		w.WriteBytes([]byte("small"))
+		w.WriteString("s")
+		r.NoError(w.Err())
+		r.Equal(b.Len()-1, w.Offset())
+
+		want := c.AppendInt32([]byte("x"), -1)
+		want = c.AppendBytes(want, big)
+		want = c.AppendBytes(want, []byte("small"))
+		want = c.AppendString(want, "s")
+		var out bytes.Buffer
+		_, err := b.WriteTo(&out)
+		r.NoError(err)
+		r.Equal(want, out.Bytes())
+
+		b.seal()
+		r.True(aliases(b.segs[1], unsafe.Pointer(&big[0]), len(big)), "%T", c)
+		b.Reset()
+	}
+}
+
+// sizedAppender records whether AppendBinary is given room for its size.
+type sizedAppender struct {
+	n     int
+	roomy bool
+}
+
+func (v *sizedAppender) SizeBinary() int {
+	return v.n
+}
+
+func (v *sizedAppender) AppendBinary(dst []byte) ([]byte, error) {
+	v.roomy = cap(dst)-len(dst) >= v.n
+	return append(dst, make([]byte, v.n)...), nil
+}
@@ -0,0 +1,377 @@
+package gobin
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"slices"
+)
+
+// stream.go writes and reads length-delimited frames, one value per frame:
+// [len:uint32][v:[]byte], the length in little endian, or with checksums the
+// frames of WriteFrame.
+
+const (
+	frameHeaderSize = 4
+
+	// DefaultMaxFrameSize is the largest frame a Decoder accepts unless told
+	// otherwise.
+	DefaultMaxFrameSize = 4 << 20
+)
+
+var ErrFrameTooLarge = errors.New("frame too large")
+
+// Encoder writes values to an io.Writer as length-delimited frames.
+type Encoder struct {
+	w         io.Writer
+	buf       Buffer
+	checksum  bool
+	threshold int
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// SetChecksum makes Encode write checksummed frames, as WriteFrame does. The
+// Decoder must be set to read them.
+func (e *Encoder) SetChecksum(on bool) {
+	e.checksum = on
+}
+
+// SetCompression makes Encode compress frames of at least threshold bytes,
+// as WriteCompressedFrame does, e.g. DefaultCompressThreshold. As only
+// checksummed frames can be compressed, it turns checksums on. A threshold of
+// zero turns compression off.
+func (e *Encoder) SetCompression(threshold int) {
+	e.threshold = threshold
+	if threshold > 0 {
+		e.checksum = true
+	}
+}
+
+// Encode writes v as a single frame.
+func (e *Encoder) Encode(v MarshalerTo) error {
+	if e.checksum {
+		e.buf.Reset()
+		if err := writeFrame(&e.buf, v, e.threshold); err != nil {
+			return err
+		}
+		_, err := e.buf.WriteTo(e.w)
+		return err
+	}
+	sz := v.SizeBinary()
+	if uint64(sz) > math.MaxUint32 {
+		return ErrFrameTooLarge
+	}
+	if c := frameHeaderSize + sz; cap(e.buf.Bytes) < c {
+		e.buf.Bytes = make([]byte, c)
+	} else {
+		e.buf.Bytes = e.buf.Bytes[:c]
+	}
+	if _, err := marshalSafeInteger32(uint32(sz), e.buf.Bytes); err != nil {
+		return err
+	}
+	n, err := v.MarshalTo(e.buf.Bytes[frameHeaderSize:])
+	if err != nil {
+		return err
+	}
+	if n != sz {
+		return fmt.Errorf("%s size / offset different %d : %d", "Encode", sz, n)
+	}
+	_, err = e.buf.WriteTo(e.w)
+	return err
+}
+
+// Decoder reads length-delimited frames from an io.Reader.
+//
+// Frames are read into a Buffer taken from the pool. Decoded values that
+// alias their input, such as []byte fields, are only valid until the next
+// call to Decode or Release.
+type Decoder struct {
+	r            io.Reader
+	buf          *Buffer
+	maxFrameSize int
+	hdr          [checksumFrameHeaderSize]byte
+	checksum     bool
+	// inflated holds the payload of a compressed frame.
+	inflated []byte
+	// win holds the bytes read ahead of r, from a damaged frame on while
+	// resynchronising, and pos the offset of the next one to read.
+	win []byte
+	pos int
+	// crcs holds the CRC-32C of each prefix of win while resynchronising.
+	crcs []uint32
+	// resync is set after a damaged frame, until the next valid one.
+	resync bool
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r, maxFrameSize: DefaultMaxFrameSize}
+}
+
+// SetMaxFrameSize sets the size of the largest frame Decode accepts. Larger
+// frames are rejected with ErrFrameTooLarge before anything is allocated.
+func (d *Decoder) SetMaxFrameSize(n int) {
+	d.maxFrameSize = n
+}
+
+// SetChecksum makes Decode read checksummed frames, as written by an Encoder
+// set to write them.
+func (d *Decoder) SetChecksum(on bool) {
+	d.checksum = on
+}
+
+// Decode reads the next frame into v. It returns io.EOF when there are no
+// more frames.
+//
+// With checksums, compressed frames are decompressed, their size limited as
+// that of any other frame. A frame failing its checksum returns ErrChecksumMismatch,
+// one too large or truncated ErrFrameTooLarge or io.ErrUnexpectedEOF, and the
+// next call resynchronises: it skips ahead a byte at a time to the next frame
+// passing its checksum, rather than wait for the rest of a frame when a later
+// one is read in full.
+func (d *Decoder) Decode(v UnmarshalerFrom) error {
+	compressed, err := d.next()
+	if err != nil {
+		return err
+	}
+	payload := d.buf.Bytes
+	if compressed {
+		if d.inflated, err = decompress(d.inflated[:0], payload, d.maxFrameSize); err != nil {
+			return err
+		}
+		payload = d.inflated
+	}
+	n, err := v.UnmarshalFrom(payload)
+	if err != nil {
+		return err
+	}
+	if n != len(payload) {
+		return fmt.Errorf("%s size / offset different %d : %d", "Decode", len(payload), n)
+	}
+	return nil
+}
+
+// next reads the next frame into d.buf and reports whether it is compressed.
+func (d *Decoder) next() (compressed bool, err error) {
+	if d.resync {
+		return d.resynchronise()
+	}
+	if d.pos == len(d.win) {
+		d.win, d.pos = nil, 0
+	}
+	start := d.pos
+	hdr := d.hdr[:frameHeaderSize]
+	if d.checksum {
+		hdr = d.hdr[:]
+	}
+	if _, err := d.readFull(hdr); err != nil {
+		return false, err
+	}
+	l, _, err := unmarshalSafeInteger32[uint32](hdr)
+	if err != nil {
+		return false, err
+	}
+	if d.checksum {
+		compressed, l = l&frameCompressed != 0, l&^frameCompressed
+	}
+	if uint64(l) > uint64(d.maxFrameSize) {
+		if d.checksum {
+			d.damaged(start, hdr, nil)
+		}
+		return false, ErrFrameTooLarge
+	}
+	payload := d.payload(int(l))
+	if n, err := d.readFull(payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if d.checksum {
+			d.damaged(start, hdr, payload[:n])
+		}
+		return false, err
+	}
+	if d.checksum {
+		if err := checkFrame(hdr, payload); err != nil {
+			d.damaged(start, hdr, payload)
+			return false, err
+		}
+	}
+	return compressed, nil
+}
+
+// payload returns d.buf resized to l bytes.
+func (d *Decoder) payload(l int) []byte {
+	if d.buf == nil {
+		d.buf = NewBufferFromPoolWithCap(l)
+	} else if cap(d.buf.Bytes) < l {
+		d.buf.Bytes = make([]byte, 0, l)
+	}
+	d.buf.Bytes = d.buf.Bytes[:l]
+	return d.buf.Bytes
+}
+
+// damaged starts resynchronising after a damaged frame read from offset start
+// of d.win, with header hdr and payload: its bytes but the first are looked
+// at again, in place if they were all in d.win.
+func (d *Decoder) damaged(start int, hdr, payload []byte) {
+	if d.pos-start == len(hdr)+len(payload) {
+		d.win = d.win[start+1:]
+	} else {
+		// The frame was read past d.win, which is left empty.
+		d.win = append(append(make([]byte, 0, len(hdr)+len(payload)), hdr[1:]...), payload...)
+	}
+	d.pos = 0
+	d.resync = true
+}
+
+// resynchronise leaves in d.buf the first frame of d.win passing its
+// checksum, reading more of r as needed, and leaves the bytes past it in
+// d.win.
+//
+// Candidate frames are checked in place, their checksums taken from the
+// running ones of d.win, so that skipping a byte costs the same whatever the
+// length it holds. A candidate extending past the bytes read so far is passed
+// over for a later one read in full and passing its checksum rather than
+// waited for, so that a damaged length does not block the Decoder on a live
+// stream.
+func (d *Decoder) resynchronise() (compressed bool, err error) {
+	d.crcs = appendCRCs(append(d.crcs[:0], 0), d.win)
+	// pending holds the offsets of the candidates extending past d.win.
+	var pending []int
+	for p := 0; ; {
+		kept := pending[:0]
+		for _, q := range pending {
+			ok, more := d.check(q)
+			if ok {
+				return d.accept(q), nil
+			}
+			if more {
+				kept = append(kept, q)
+			}
+		}
+		pending = kept
+		for ; p+checksumFrameHeaderSize <= len(d.win); p++ {
+			ok, more := d.check(p)
+			if ok {
+				return d.accept(p), nil
+			}
+			if more {
+				pending = append(pending, p)
+			}
+		}
+		// Drop the bytes no candidate starts from any more before reading
+		// more, when they are at least half of d.win.
+		start := p
+		if len(pending) > 0 {
+			start = pending[0]
+		}
+		if start > 0 && start >= len(d.win)/2 {
+			d.win = d.win[:copy(d.win, d.win[start:])]
+			d.crcs = d.crcs[:copy(d.crcs, d.crcs[start:])]
+			p -= start
+			for i := range pending {
+				pending[i] -= start
+			}
+		}
+		if err := d.fill(); err != nil {
+			if err == io.EOF {
+				d.win, d.crcs, d.resync = nil, nil, false
+				err = io.ErrUnexpectedEOF
+			}
+			return false, err
+		}
+	}
+}
+
+// check reports whether the candidate frame at offset p of d.win passes its
+// checksum, or else whether it extends past d.win.
+func (d *Decoder) check(p int) (ok, more bool) {
+	hdr := d.win[p : p+checksumFrameHeaderSize]
+	l, _, _ := unmarshalSafeInteger32[uint32](hdr)
+	l &^= frameCompressed
+	if uint64(l) > uint64(d.maxFrameSize) {
+		return false, false
+	}
+	from, to := p+checksumFrameHeaderSize, p+checksumFrameHeaderSize+int(l)
+	if to > len(d.win) {
+		return false, true
+	}
+	want, _, _ := unmarshalSafeInteger32[uint32](hdr[frameHeaderSize:])
+	crc := crc32.Checksum(hdr[:frameHeaderSize], castagnoli)
+	if l < 1<<10 {
+		crc = crc32.Update(crc, castagnoli, d.win[from:to])
+	} else {
+		crc = crcShift(crc^d.crcs[from], int(l)) ^ d.crcs[to]
+	}
+	return crc == want, false
+}
+
+// accept reads the frame at offset p of d.win into d.buf, ending
+// resynchronisation, and reports whether it is compressed.
+func (d *Decoder) accept(p int) (compressed bool) {
+	l, _, _ := unmarshalSafeInteger32[uint32](d.win[p:])
+	compressed, l = l&frameCompressed != 0, l&^frameCompressed
+	from := p + checksumFrameHeaderSize
+	copy(d.payload(int(l)), d.win[from:])
+	d.pos = from + int(l)
+	d.crcs, d.resync = nil, false
+	return compressed
+}
+
+// fill reads at least one more byte into d.win, unless r fails.
+func (d *Decoder) fill() error {
+	if len(d.win) == cap(d.win) {
+		d.win = slices.Grow(d.win, max(len(d.win), 512))
+	}
+	for {
+		n, err := d.r.Read(d.win[len(d.win):cap(d.win)])
+		if n > 0 {
+			d.win = d.win[:len(d.win)+n]
+			d.crcs = appendCRCs(d.crcs, d.win[len(d.win)-n:])
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// appendCRCs appends to crcs, ending with the CRC-32C of some bytes, those of
+// the bytes followed by each prefix of p.
+func appendCRCs(crcs []uint32, p []byte) []uint32 {
+	c := ^crcs[len(crcs)-1]
+	for _, b := range p {
+		c = castagnoli[byte(c)^b] ^ c>>8
+		crcs = append(crcs, ^c)
+	}
+	return crcs
+}
+
+// readFull fills p from d.win, then from the underlying reader.
+func (d *Decoder) readFull(p []byte) (int, error) {
+	n := copy(p, d.win[d.pos:])
+	d.pos += n
+	if n == len(p) {
+		return n, nil
+	}
+	m, err := io.ReadFull(d.r, p[n:])
+	if err == io.EOF && n > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n + m, err
+}
+
+// Release returns the Decoder's buffer to the pool. Values decoded so far
+// must no longer be used if they alias it.
+func (d *Decoder) Release() {
+	if d.buf != nil {
+		d.buf.ReturnToPool()
+		d.buf = nil
+	}
+}
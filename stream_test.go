@@ -0,0 +1,90 @@
+package gobin
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type streamValue struct {
+	Safe
+	ID   uint32
+	Name string
+}
+
+func (o *streamValue) SizeBinary() int {
+	return 4 + 8 + len(o.Name)
+}
+
+func (o *streamValue) MarshalTo(data []byte) (int, error) {
+	var (
+		offset, n int
+		err       error
+	)
+	if n, err = o.MarshalUint32(o.ID, data[offset:]); err != nil {
+		return 0, err
+	}
+	offset += n
+	if n, err = o.MarshalString(o.Name, data[offset:]); err != nil {
+		return 0, err
+	}
+	offset += n
+	return offset, nil
+}
+
+func (o *streamValue) UnmarshalFrom(data []byte) (int, error) {
+	var (
+		i, n int
+		err  error
+	)
+	if o.ID, i, err = o.UnmarshalUint32(data[n:]); err != nil {
+		return 0, err
+	}
+	n += i
+	if o.Name, i, err = o.UnmarshalString(data[n:]); err != nil {
+		return 0, err
+	}
+	n += i
+	return n, nil
+}
+
+func TestStream(t *testing.T) {
+	r := require.New(t)
+	values := []*streamValue{
+		{ID: 1, Name: "hello"},
+		{ID: 2, Name: ""},
+		{ID: 3, Name: "hello world"},
+	}
+	t.Run("round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		for _, v := range values {
+			r.NoError(enc.Encode(v))
+		}
+		dec := NewDecoder(&buf)
+		defer dec.Release()
+		for _, v := range values {
+			var v2 streamValue
+			r.NoError(dec.Decode(&v2))
+			r.Equal(*v, v2)
+		}
+		r.ErrorIs(dec.Decode(&streamValue{}), io.EOF)
+	})
+	t.Run("should return ErrFrameTooLarge if a frame exceeds the limit", func(t *testing.T) {
+		var buf bytes.Buffer
+		r.NoError(NewEncoder(&buf).Encode(values[2]))
+		dec := NewDecoder(&buf)
+		defer dec.Release()
+		dec.SetMaxFrameSize(values[2].SizeBinary() - 1)
+		r.ErrorIs(dec.Decode(&streamValue{}), ErrFrameTooLarge)
+	})
+	t.Run("should return io.ErrUnexpectedEOF on a truncated frame", func(t *testing.T) {
+		var buf bytes.Buffer
+		r.NoError(NewEncoder(&buf).Encode(values[0]))
+		dec := NewDecoder(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+		defer dec.Release()
+		r.ErrorIs(dec.Decode(&streamValue{}), io.ErrUnexpectedEOF)
+	})
+}
@@ -0,0 +1,103 @@
+package gobin
+
+import (
+	"math"
+	"time"
+)
+
+// A time.Time is encoded as [ticks:int64][offset:int16] with the integer
+// encoding of the codec, where ticks is the number of 100ns intervals since
+// 0001-01-01 00:00:00 UTC, the date type of the schema language, and offset
+// is the zone offset in minutes east of UTC.
+//
+// Decoded times are in time.UTC if the offset is zero and in a time.FixedZone
+// otherwise: the instant and the offset survive a round trip, the zone name,
+// sub-100ns precision and seconds of the offset do not. Times are
+// representable from about 29,000 years before to 29,000 years after the
+// epoch; MarshalTime returns ErrOverflow outside that range, AppendTime
+// saturates.
+//
+// A time.Duration is encoded as its int64 number of nanoseconds.
+const (
+	ticksPerSecond = 10_000_000
+	nanosPerTick   = 100
+	// epochToUnix is the number of seconds from 0001-01-01 to 1970-01-01.
+	epochToUnix = 62_135_596_800
+)
+
+// timeTicks returns the ticks and offset of t. On overflow ticks is
+// saturated and err is ErrOverflow.
+func timeTicks(t time.Time) (ticks int64, offset int16, err error) {
+	_, off := t.Zone()
+	offset = int16(off / 60)
+	sec := t.Unix() + epochToUnix
+	switch {
+	case sec >= math.MaxInt64/ticksPerSecond:
+		return math.MaxInt64, offset, ErrOverflow
+	case sec < math.MinInt64/ticksPerSecond:
+		return math.MinInt64, offset, ErrOverflow
+	}
+	return sec*ticksPerSecond + int64(t.Nanosecond()/nanosPerTick), offset, nil
+}
+
+// timeFromTicks is the inverse of timeTicks.
+func timeFromTicks(ticks int64, offset int16) time.Time {
+	sec, rem := ticks/ticksPerSecond, ticks%ticksPerSecond
+	if rem < 0 {
+		sec--
+		rem += ticksPerSecond
+	}
+	t := time.Unix(sec-epochToUnix, rem*nanosPerTick)
+	if offset == 0 {
+		return t.UTC()
+	}
+	return t.In(time.FixedZone("", int(offset)*60))
+}
+
+type timeMarshaler interface {
+	MarshalInt64(int64, []byte) (int, error)
+	MarshalInt16(int16, []byte) (int, error)
+}
+
+type timeUnmarshaler interface {
+	UnmarshalInt64([]byte) (int64, int, error)
+	UnmarshalInt16([]byte) (int16, int, error)
+}
+
+type timeAppender interface {
+	AppendInt64([]byte, int64) []byte
+	AppendInt16([]byte, int16) []byte
+}
+
+func marshalTime(m timeMarshaler, v time.Time, bs []byte) (n int, err error) {
+	ticks, offset, err := timeTicks(v)
+	if err != nil {
+		return 0, err
+	}
+	if n, err = m.MarshalInt64(ticks, bs); err != nil {
+		return 0, err
+	}
+	i, err := m.MarshalInt16(offset, bs[n:])
+	if err != nil {
+		return 0, err
+	}
+	return n + i, nil
+}
+
+func unmarshalTime(u timeUnmarshaler, bs []byte) (v time.Time, n int, err error) {
+	ticks, n, err := u.UnmarshalInt64(bs)
+	if err != nil {
+		return
+	}
+	offset, i, err := u.UnmarshalInt16(bs[n:])
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return timeFromTicks(ticks, offset), n + i, nil
+}
+
+func appendTime(a timeAppender, dst []byte, v time.Time) []byte {
+	ticks, offset, _ := timeTicks(v)
+	dst = a.AppendInt64(dst, ticks)
+	return a.AppendInt16(dst, offset)
+}
@@ -0,0 +1,72 @@
+package gobin
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fullCodec interface {
+	Marshaler
+	Unmarshaler
+	Appender
+}
+
+func TestTime(t *testing.T) {
+	r := require.New(t)
+	times := []time.Time{
+		{},
+		time.Date(2024, 5, 6, 7, 8, 9, 123456700, time.UTC),
+		time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("CEST", 2*3600)),
+		time.Date(1969, 12, 31, 23, 59, 59, 900, time.FixedZone("", -(5*3600+30*60))),
+		time.Date(-100, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, c := range []fullCodec{Safe{}, Unsafe{}, BigEndian{}, Varint{}} {
+		for _, v := range times {
+			bs := make([]byte, 32)
+			n, err := c.MarshalTime(v, bs)
+			r.NoError(err)
+			r.Equal(bs[:n], c.AppendTime(nil, v))
+			v2, n2, err := c.UnmarshalTime(bs)
+			r.NoError(err)
+			r.Equal(n, n2)
+			r.True(v.Equal(v2), "%v != %v", v, v2)
+			_, off := v.Zone()
+			_, off2 := v2.Zone()
+			r.Equal(off, off2)
+
+			_, _, err = c.UnmarshalTime(bs[:n-1])
+			r.ErrorIs(err, ErrNotEnoughSpace)
+		}
+		bs := make([]byte, 16)
+		n, err := c.MarshalDuration(-time.Minute, bs)
+		r.NoError(err)
+		d, _, err := c.UnmarshalDuration(bs[:n])
+		r.NoError(err)
+		r.Equal(-time.Minute, d)
+	}
+	t.Run("ticks since year 1", func(t *testing.T) {
+		bs := AppendTime(nil, time.Date(1, 1, 1, 0, 0, 1, 50, time.UTC))
+		r.Equal([]byte{0x80, 0x96, 0x98, 0, 0, 0, 0, 0, 0, 0}, bs)
+		v, _, err := Safe{}.UnmarshalTime(make([]byte, 10))
+		r.NoError(err)
+		r.Equal(time.Time{}, v)
+	})
+	t.Run("should return ErrOverflow outside the range", func(t *testing.T) {
+		_, err := Safe{}.MarshalTime(time.Unix(math.MaxInt64/ticksPerSecond, 0), make([]byte, 10))
+		r.ErrorIs(err, ErrOverflow)
+	})
+	t.Run("reader and writer", func(t *testing.T) {
+		var buf Buffer
+		w := NewWriter(Varint{}, &buf)
+		w.WriteTime(times[2])
+		w.WriteDuration(time.Second)
+		r.NoError(w.Err())
+		rd := NewReader(Varint{}, buf.Bytes)
+		r.True(times[2].Equal(rd.ReadTime()))
+		r.Equal(time.Second, rd.ReadDuration())
+		r.NoError(rd.Err())
+	})
+}
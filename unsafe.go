@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"time"
 	"unsafe"
 )
 
@@ -15,13 +16,15 @@ var (
 	unmarshalUnsafeUint func(bs []byte) (v uint, n int, err error)
 )
 
+// As with Safe, int, uint and all length prefixes are encoded as 64-bit
+// values on every platform.
 func init() {
 	switch strconv.IntSize {
 	case 32:
-		marshalUnsafeInt = marshalUnsafeInteger32[int]
-		unmarshalUnsafeInt = unmarshalUnsafeInteger32[int]
-		marshalUnsafeUint = marshalUnsafeInteger32[uint]
-		unmarshalUnsafeUint = unmarshalUnsafeInteger32[uint]
+		marshalUnsafeInt = marshalUnsafeWideInt
+		unmarshalUnsafeInt = unmarshalUnsafeNarrowInt
+		marshalUnsafeUint = marshalUnsafeWideUint
+		unmarshalUnsafeUint = unmarshalUnsafeNarrowUint
 	case 64:
 		marshalUnsafeInt = marshalUnsafeInteger64[int]
 		unmarshalUnsafeInt = unmarshalUnsafeInteger64[int]
@@ -32,10 +35,45 @@ func init() {
 	}
 }
 
+// marshalUnsafeWideInt encodes a 32-bit int as a 64-bit int.
+func marshalUnsafeWideInt(v int, bs []byte) (int, error) {
+	return marshalUnsafeInteger64(int64(v), bs)
+}
+
+// marshalUnsafeWideUint encodes a 32-bit uint as a 64-bit uint.
+func marshalUnsafeWideUint(v uint, bs []byte) (int, error) {
+	return marshalUnsafeInteger64(uint64(v), bs)
+}
+
+// unmarshalUnsafeNarrowInt decodes a 64-bit int into a 32-bit int.
+func unmarshalUnsafeNarrowInt(bs []byte) (v int, n int, err error) {
+	i, n, err := unmarshalUnsafeInteger64[int64](bs)
+	if err != nil {
+		return
+	}
+	if i < math.MinInt32 || i > math.MaxInt32 {
+		return 0, 0, ErrOverflow
+	}
+	return int(i), n, nil
+}
+
+// unmarshalUnsafeNarrowUint decodes a 64-bit uint into a 32-bit uint.
+func unmarshalUnsafeNarrowUint(bs []byte) (v uint, n int, err error) {
+	i, n, err := unmarshalUnsafeInteger64[uint64](bs)
+	if err != nil {
+		return
+	}
+	if i > math.MaxUint32 {
+		return 0, 0, ErrOverflow
+	}
+	return uint(i), n, nil
+}
+
 var (
 	ErrNotEnoughSpace = errors.New("not enough space")
 	ErrInvalidBool    = errors.New("invalid bool value")
 	ErrNegativeLength = errors.New("negative length")
+	ErrOverflow       = errors.New("value overflows target type")
 )
 
 func marshalUnsafeInteger8[T Integer8](t T, bs []byte) (int, error) {
@@ -98,7 +136,20 @@ func unmarshalUnsafeInteger64[T Integer64](bs []byte) (t T, n int, err error) {
 	return *(*T)(unsafe.Pointer(&bs[0])), 8, nil
 }
 
+func appendUnsafeInteger16[T Integer16](dst []byte, t T) []byte {
+	return append(dst, (*[2]byte)(unsafe.Pointer(&t))[:]...)
+}
+
+func appendUnsafeInteger32[T Integer32](dst []byte, t T) []byte {
+	return append(dst, (*[4]byte)(unsafe.Pointer(&t))[:]...)
+}
+
+func appendUnsafeInteger64[T Integer64](dst []byte, t T) []byte {
+	return append(dst, (*[8]byte)(unsafe.Pointer(&t))[:]...)
+}
+
 var _ Marshaler = Unsafe{}
+var _ Appender = Unsafe{}
 
 type Unsafe struct{}
 
@@ -202,6 +253,9 @@ func (Unsafe) UnmarshalUint64(bs []byte) (v uint64, n int, err error) {
 
 func (Unsafe) MarshalString(v string, bs []byte) (n int, err error) {
 	n, err = marshalUnsafeInt(len(v), bs)
+	if err != nil {
+		return
+	}
 	if len(bs[n:]) < len(v) {
 		return 0, ErrNotEnoughSpace
 	}
@@ -217,15 +271,26 @@ func (Unsafe) UnmarshalString(bs []byte) (v string, n int, err error) {
 		err = ErrNegativeLength
 		return
 	}
-	if l == 0 {
-		return "", n, nil
-	}
 	if len(bs[n:]) < int(l) {
 		err = ErrNotEnoughSpace
 		return
 	}
-	c := bs[n : n+l]
-	return unsafe.String(&c[0], len(c)), n + l, nil
+	return aliasString(bs[n : n+l]), n + l, nil
+}
+
+// BorrowString is UnmarshalString, which aliases bs already.
+func (c Unsafe) BorrowString(bs []byte) (v string, n int, err error) {
+	return c.UnmarshalString(bs)
+}
+
+func (Unsafe) aliasesStrings() {}
+
+// aliasString returns b as a string sharing its memory.
+func aliasString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(&b[0], len(b))
 }
 
 func (Unsafe) MarshalByte(v byte, bs []byte) (int, error) {
@@ -290,6 +355,113 @@ func (Unsafe) UnmarshalFloat64(bs []byte) (v float64, n int, err error) {
 	return math.Float64frombits(uv), n, nil
 }
 
+func (Unsafe) AppendBool(dst []byte, v bool) []byte {
+	return appendBool(dst, v)
+}
+
+func (Unsafe) AppendInt(dst []byte, v int) []byte {
+	return appendUnsafeInteger64(dst, int64(v))
+}
+
+func (Unsafe) AppendInt8(dst []byte, v int8) []byte {
+	return append(dst, byte(v))
+}
+
+func (Unsafe) AppendInt16(dst []byte, v int16) []byte {
+	return appendUnsafeInteger16(dst, v)
+}
+
+func (Unsafe) AppendInt32(dst []byte, v int32) []byte {
+	return appendUnsafeInteger32(dst, v)
+}
+
+func (Unsafe) AppendInt64(dst []byte, v int64) []byte {
+	return appendUnsafeInteger64(dst, v)
+}
+
+func (Unsafe) AppendUint(dst []byte, v uint) []byte {
+	return appendUnsafeInteger64(dst, uint64(v))
+}
+
+func (Unsafe) AppendUint8(dst []byte, v uint8) []byte {
+	return append(dst, byte(v))
+}
+
+func (Unsafe) AppendUint16(dst []byte, v uint16) []byte {
+	return appendUnsafeInteger16(dst, v)
+}
+
+func (Unsafe) AppendUint32(dst []byte, v uint32) []byte {
+	return appendUnsafeInteger32(dst, v)
+}
+
+func (Unsafe) AppendUint64(dst []byte, v uint64) []byte {
+	return appendUnsafeInteger64(dst, v)
+}
+
+func (Unsafe) AppendFloat32(dst []byte, v float32) []byte {
+	return appendUnsafeInteger32(dst, math.Float32bits(v))
+}
+
+func (Unsafe) AppendFloat64(dst []byte, v float64) []byte {
+	return appendUnsafeInteger64(dst, math.Float64bits(v))
+}
+
+func (Unsafe) AppendString(dst []byte, v string) []byte {
+	dst = appendUnsafeInteger64(dst, int64(len(v)))
+	return append(dst, v...)
+}
+
+func (Unsafe) AppendByte(dst []byte, v byte) []byte {
+	return append(dst, byte(v))
+}
+
+func (c Unsafe) AppendBytes(dst []byte, v []byte) []byte {
+	return append(c.appendBytesPrefix(dst, v), v...)
+}
+
+// appendBytesPrefix appends the length preceding v.
+func (Unsafe) appendBytesPrefix(dst []byte, v []byte) []byte {
+	return appendUnsafeInteger64(dst, int64(len(v)))
+}
+
+func (c Unsafe) MarshalTime(v time.Time, bs []byte) (n int, err error) {
+	return marshalTime(c, v, bs)
+}
+
+func (c Unsafe) UnmarshalTime(bs []byte) (v time.Time, n int, err error) {
+	return unmarshalTime(c, bs)
+}
+
+func (c Unsafe) AppendTime(dst []byte, v time.Time) []byte {
+	return appendTime(c, dst, v)
+}
+
+func (c Unsafe) MarshalDuration(v time.Duration, bs []byte) (n int, err error) {
+	return c.MarshalInt64(int64(v), bs)
+}
+
+func (c Unsafe) UnmarshalDuration(bs []byte) (v time.Duration, n int, err error) {
+	i, n, err := c.UnmarshalInt64(bs)
+	return time.Duration(i), n, err
+}
+
+func (c Unsafe) AppendDuration(dst []byte, v time.Duration) []byte {
+	return c.AppendInt64(dst, int64(v))
+}
+
+func (Unsafe) MarshalGUID(v GUID, bs []byte) (n int, err error) {
+	return marshalGUID(v, bs)
+}
+
+func (Unsafe) UnmarshalGUID(bs []byte) (v GUID, n int, err error) {
+	return unmarshalGUID(bs)
+}
+
+func (Unsafe) AppendGUID(dst []byte, v GUID) []byte {
+	return appendGUID(dst, v)
+}
+
 func (Unsafe) MarshalBinary() ([]byte, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
@@ -254,3 +254,28 @@ func TestUnsafe(t *testing.T) {
 		r.Equal(bs, bs3)
 	})
 }
+
+func TestUnsafeIntWidth(t *testing.T) {
+	r := require.New(t)
+	v := Unsafe{}
+	defer func(m func(int, []byte) (int, error), u func([]byte) (int, int, error)) {
+		marshalUnsafeInt, unmarshalUnsafeInt = m, u
+	}(marshalUnsafeInt, unmarshalUnsafeInt)
+	// encode and decode as a host with a 32-bit int would
+	marshalUnsafeInt, unmarshalUnsafeInt = marshalUnsafeWideInt, unmarshalUnsafeNarrowInt
+
+	bs := make([]byte, 8)
+	n, err := v.MarshalInt(-2, bs)
+	r.NoError(err)
+	r.Equal(8, n)
+	i, n, err := v.UnmarshalInt(bs)
+	r.NoError(err)
+	r.Equal(8, n)
+	r.Equal(-2, i)
+
+	_, err = v.MarshalInt64(math.MinInt32-1, bs)
+	r.NoError(err)
+	_, n, err = v.UnmarshalInt(bs)
+	r.ErrorIs(err, ErrOverflow)
+	r.Equal(0, n)
+}
@@ -0,0 +1,444 @@
+package gobin
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var _ Marshaler = Varint{}
+var _ Unmarshaler = Varint{}
+var _ Appender = Varint{}
+
+func zigzag64(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func unzigzag64(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func marshalUvarint(v uint64, bs []byte) (n int, err error) {
+	if len(bs) < sizeUvarint(v) {
+		return 0, ErrNotEnoughSpace
+	}
+	return binary.PutUvarint(bs, v), nil
+}
+
+func unmarshalUvarint(bs []byte, max uint64) (v uint64, n int, err error) {
+	v, n = binary.Uvarint(bs)
+	if n == 0 {
+		return 0, 0, ErrNotEnoughSpace
+	}
+	if n < 0 || v > max {
+		return 0, 0, ErrOverflow
+	}
+	return v, n, nil
+}
+
+func unmarshalVarint(bs []byte, min, max int64) (v int64, n int, err error) {
+	uv, n, err := unmarshalUvarint(bs, math.MaxUint64)
+	if err != nil {
+		return
+	}
+	v = unzigzag64(uv)
+	if v < min || v > max {
+		return 0, 0, ErrOverflow
+	}
+	return v, n, nil
+}
+
+func sizeUvarint(v uint64) int {
+	n := 1
+	for v >= 0x80 {
+		v >>= 7
+		n++
+	}
+	return n
+}
+
+// Varint encodes integers as LEB128 varints, signed integers zigzag encoded
+// first, and prefixes strings and bytes with a varint length.
+// bool, int8, uint8 and byte take a single byte; floats are fixed width
+// little endian, as in Safe.
+//
+// Since the encoded size depends on the value, Varint also provides a Size
+// method for every type it marshals.
+type Varint struct{}
+
+func (Varint) MarshalBool(v bool, bs []byte) (n int, err error) {
+	return marshalBool(v, bs)
+}
+
+func (Varint) UnmarshalBool(bs []byte) (v bool, n int, err error) {
+	return unmarshalBool(bs)
+}
+
+func (Varint) SizeBool(bool) int {
+	return 1
+}
+
+func (Varint) MarshalInt(v int, bs []byte) (n int, err error) {
+	return marshalUvarint(zigzag64(int64(v)), bs)
+}
+
+func (Varint) UnmarshalInt(bs []byte) (v int, n int, err error) {
+	iv, n, err := unmarshalVarint(bs, math.MinInt, math.MaxInt)
+	return int(iv), n, err
+}
+
+func (Varint) SizeInt(v int) int {
+	return sizeUvarint(zigzag64(int64(v)))
+}
+
+func (Varint) MarshalInt8(v int8, bs []byte) (n int, err error) {
+	return marshalSafeInteger8(v, bs)
+}
+
+func (Varint) UnmarshalInt8(bs []byte) (v int8, n int, err error) {
+	return unmarshalSafeInteger8[int8](bs)
+}
+
+func (Varint) SizeInt8(int8) int {
+	return 1
+}
+
+func (Varint) MarshalInt16(v int16, bs []byte) (n int, err error) {
+	return marshalUvarint(zigzag64(int64(v)), bs)
+}
+
+func (Varint) UnmarshalInt16(bs []byte) (v int16, n int, err error) {
+	iv, n, err := unmarshalVarint(bs, math.MinInt16, math.MaxInt16)
+	return int16(iv), n, err
+}
+
+func (Varint) SizeInt16(v int16) int {
+	return sizeUvarint(zigzag64(int64(v)))
+}
+
+func (Varint) MarshalInt32(v int32, bs []byte) (n int, err error) {
+	return marshalUvarint(zigzag64(int64(v)), bs)
+}
+
+func (Varint) UnmarshalInt32(bs []byte) (v int32, n int, err error) {
+	iv, n, err := unmarshalVarint(bs, math.MinInt32, math.MaxInt32)
+	return int32(iv), n, err
+}
+
+func (Varint) SizeInt32(v int32) int {
+	return sizeUvarint(zigzag64(int64(v)))
+}
+
+func (Varint) MarshalInt64(v int64, bs []byte) (n int, err error) {
+	return marshalUvarint(zigzag64(v), bs)
+}
+
+func (Varint) UnmarshalInt64(bs []byte) (v int64, n int, err error) {
+	return unmarshalVarint(bs, math.MinInt64, math.MaxInt64)
+}
+
+func (Varint) SizeInt64(v int64) int {
+	return sizeUvarint(zigzag64(v))
+}
+
+func (Varint) MarshalUint(v uint, bs []byte) (n int, err error) {
+	return marshalUvarint(uint64(v), bs)
+}
+
+func (Varint) UnmarshalUint(bs []byte) (v uint, n int, err error) {
+	uv, n, err := unmarshalUvarint(bs, math.MaxUint)
+	return uint(uv), n, err
+}
+
+func (Varint) SizeUint(v uint) int {
+	return sizeUvarint(uint64(v))
+}
+
+func (Varint) MarshalUint8(v uint8, bs []byte) (n int, err error) {
+	return marshalSafeInteger8(v, bs)
+}
+
+func (Varint) UnmarshalUint8(bs []byte) (v uint8, n int, err error) {
+	return unmarshalSafeInteger8[uint8](bs)
+}
+
+func (Varint) SizeUint8(uint8) int {
+	return 1
+}
+
+func (Varint) MarshalUint16(v uint16, bs []byte) (n int, err error) {
+	return marshalUvarint(uint64(v), bs)
+}
+
+func (Varint) UnmarshalUint16(bs []byte) (v uint16, n int, err error) {
+	uv, n, err := unmarshalUvarint(bs, math.MaxUint16)
+	return uint16(uv), n, err
+}
+
+func (Varint) SizeUint16(v uint16) int {
+	return sizeUvarint(uint64(v))
+}
+
+func (Varint) MarshalUint32(v uint32, bs []byte) (n int, err error) {
+	return marshalUvarint(uint64(v), bs)
+}
+
+func (Varint) UnmarshalUint32(bs []byte) (v uint32, n int, err error) {
+	uv, n, err := unmarshalUvarint(bs, math.MaxUint32)
+	return uint32(uv), n, err
+}
+
+func (Varint) SizeUint32(v uint32) int {
+	return sizeUvarint(uint64(v))
+}
+
+func (Varint) MarshalUint64(v uint64, bs []byte) (n int, err error) {
+	return marshalUvarint(v, bs)
+}
+
+func (Varint) UnmarshalUint64(bs []byte) (v uint64, n int, err error) {
+	return unmarshalUvarint(bs, math.MaxUint64)
+}
+
+func (Varint) SizeUint64(v uint64) int {
+	return sizeUvarint(v)
+}
+
+// MarshalString encodes v as [len:uvarint][v:string].
+func (Varint) MarshalString(v string, bs []byte) (n int, err error) {
+	n, err = marshalUvarint(uint64(len(v)), bs)
+	if err != nil {
+		return
+	}
+	if len(bs[n:]) < len(v) {
+		return 0, ErrNotEnoughSpace
+	}
+	return n + copy(bs[n:], v), nil
+}
+
+// UnmarshalString decodes a copy of the string at the start of bs.
+func (c Varint) UnmarshalString(bs []byte) (v string, n int, err error) {
+	v, n, err = c.BorrowString(bs)
+	return strings.Clone(v), n, err
+}
+
+// BorrowString decodes the string at the start of bs, aliasing bs.
+func (Varint) BorrowString(bs []byte) (v string, n int, err error) {
+	l, n, err := unmarshalUvarint(bs, math.MaxInt)
+	if err != nil {
+		return
+	}
+	if uint64(len(bs[n:])) < l {
+		return "", 0, ErrNotEnoughSpace
+	}
+	return aliasString(bs[n : n+int(l)]), n + int(l), nil
+}
+
+func (Varint) SizeString(v string) int {
+	return sizeUvarint(uint64(len(v))) + len(v)
+}
+
+func (Varint) MarshalByte(v byte, bs []byte) (n int, err error) {
+	return marshalSafeInteger8(v, bs)
+}
+
+func (Varint) UnmarshalByte(bs []byte) (v byte, n int, err error) {
+	return unmarshalSafeInteger8[byte](bs)
+}
+
+func (Varint) SizeByte(byte) int {
+	return 1
+}
+
+// MarshalBytes encodes v as [len:uvarint][v:[]byte]. As with Unsafe, nil and
+// empty slices share the same encoding and are decoded as nil.
+func (Varint) MarshalBytes(v []byte, bs []byte) (n int, err error) {
+	n, err = marshalUvarint(uint64(len(v)), bs)
+	if err != nil {
+		return
+	}
+	if len(bs[n:]) < len(v) {
+		return 0, ErrNotEnoughSpace
+	}
+	return n + copy(bs[n:], v), nil
+}
+
+func (Varint) UnmarshalBytes(bs []byte) (v []byte, n int, err error) {
+	l, n, err := unmarshalUvarint(bs, math.MaxInt)
+	if err != nil {
+		return
+	}
+	if l == 0 {
+		return nil, n, nil
+	}
+	if uint64(len(bs[n:])) < l {
+		return nil, 0, ErrNotEnoughSpace
+	}
+	return bs[n : n+int(l)], n + int(l), nil
+}
+
+func (Varint) SizeBytes(v []byte) int {
+	return sizeUvarint(uint64(len(v))) + len(v)
+}
+
+func (Varint) MarshalFloat32(v float32, bs []byte) (n int, err error) {
+	return marshalSafeInteger32(math.Float32bits(v), bs)
+}
+
+func (Varint) UnmarshalFloat32(bs []byte) (v float32, n int, err error) {
+	uv, n, err := unmarshalSafeInteger32[uint32](bs)
+	if err != nil {
+		return
+	}
+	return math.Float32frombits(uv), n, nil
+}
+
+func (Varint) SizeFloat32(float32) int {
+	return 4
+}
+
+func (Varint) MarshalFloat64(v float64, bs []byte) (n int, err error) {
+	return marshalSafeInteger64(math.Float64bits(v), bs)
+}
+
+func (Varint) UnmarshalFloat64(bs []byte) (v float64, n int, err error) {
+	uv, n, err := unmarshalSafeInteger64[uint64](bs)
+	if err != nil {
+		return
+	}
+	return math.Float64frombits(uv), n, nil
+}
+
+func (Varint) SizeFloat64(float64) int {
+	return 8
+}
+
+func (Varint) AppendBool(dst []byte, v bool) []byte {
+	return appendBool(dst, v)
+}
+
+func (Varint) AppendInt(dst []byte, v int) []byte {
+	return binary.AppendUvarint(dst, zigzag64(int64(v)))
+}
+
+func (Varint) AppendInt8(dst []byte, v int8) []byte {
+	return append(dst, byte(v))
+}
+
+func (Varint) AppendInt16(dst []byte, v int16) []byte {
+	return binary.AppendUvarint(dst, zigzag64(int64(v)))
+}
+
+func (Varint) AppendInt32(dst []byte, v int32) []byte {
+	return binary.AppendUvarint(dst, zigzag64(int64(v)))
+}
+
+func (Varint) AppendInt64(dst []byte, v int64) []byte {
+	return binary.AppendUvarint(dst, zigzag64(int64(v)))
+}
+
+func (Varint) AppendUint(dst []byte, v uint) []byte {
+	return binary.AppendUvarint(dst, uint64(v))
+}
+
+func (Varint) AppendUint8(dst []byte, v uint8) []byte {
+	return append(dst, byte(v))
+}
+
+func (Varint) AppendUint16(dst []byte, v uint16) []byte {
+	return binary.AppendUvarint(dst, uint64(v))
+}
+
+func (Varint) AppendUint32(dst []byte, v uint32) []byte {
+	return binary.AppendUvarint(dst, uint64(v))
+}
+
+func (Varint) AppendUint64(dst []byte, v uint64) []byte {
+	return binary.AppendUvarint(dst, uint64(v))
+}
+
+func (Varint) AppendFloat32(dst []byte, v float32) []byte {
+	return appendSafeInteger32(dst, math.Float32bits(v))
+}
+
+func (Varint) AppendFloat64(dst []byte, v float64) []byte {
+	return appendSafeInteger64(dst, math.Float64bits(v))
+}
+
+func (Varint) AppendString(dst []byte, v string) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(v)))
+	return append(dst, v...)
+}
+
+func (Varint) AppendByte(dst []byte, v byte) []byte {
+	return append(dst, byte(v))
+}
+
+func (c Varint) AppendBytes(dst []byte, v []byte) []byte {
+	return append(c.appendBytesPrefix(dst, v), v...)
+}
+
+// appendBytesPrefix appends the length preceding v.
+func (Varint) appendBytesPrefix(dst []byte, v []byte) []byte {
+	return binary.AppendUvarint(dst, uint64(len(v)))
+}
+
+func (c Varint) MarshalTime(v time.Time, bs []byte) (n int, err error) {
+	return marshalTime(c, v, bs)
+}
+
+func (c Varint) UnmarshalTime(bs []byte) (v time.Time, n int, err error) {
+	return unmarshalTime(c, bs)
+}
+
+func (c Varint) AppendTime(dst []byte, v time.Time) []byte {
+	return appendTime(c, dst, v)
+}
+
+func (c Varint) SizeTime(v time.Time) int {
+	ticks, offset, _ := timeTicks(v)
+	return c.SizeInt64(ticks) + c.SizeInt16(offset)
+}
+
+func (c Varint) MarshalDuration(v time.Duration, bs []byte) (n int, err error) {
+	return c.MarshalInt64(int64(v), bs)
+}
+
+func (c Varint) UnmarshalDuration(bs []byte) (v time.Duration, n int, err error) {
+	i, n, err := c.UnmarshalInt64(bs)
+	return time.Duration(i), n, err
+}
+
+func (c Varint) AppendDuration(dst []byte, v time.Duration) []byte {
+	return c.AppendInt64(dst, int64(v))
+}
+
+func (c Varint) SizeDuration(v time.Duration) int {
+	return c.SizeInt64(int64(v))
+}
+
+func (Varint) MarshalGUID(v GUID, bs []byte) (n int, err error) {
+	return marshalGUID(v, bs)
+}
+
+func (Varint) UnmarshalGUID(bs []byte) (v GUID, n int, err error) {
+	return unmarshalGUID(bs)
+}
+
+func (Varint) AppendGUID(dst []byte, v GUID) []byte {
+	return appendGUID(dst, v)
+}
+
+func (Varint) SizeGUID(GUID) int {
+	return 16
+}
+
+func (Varint) MarshalBinary() ([]byte, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (Varint) UnmarshalBinary([]byte) error {
+	return fmt.Errorf("unimplemented")
+}
@@ -0,0 +1,120 @@
+package gobin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarint(t *testing.T) {
+	r := require.New(t)
+	v := Varint{}
+	t.Run("int", func(t *testing.T) {
+		var tests = []struct {
+			i int64
+			n int
+		}{
+			{0, 1},
+			{-1, 1},
+			{63, 1},
+			{-64, 1},
+			{64, 2},
+			{math.MaxInt32, 5},
+			{math.MinInt64, 10},
+			{math.MaxInt64, 10},
+		}
+		for _, test := range tests {
+			bs := make([]byte, 10)
+			n, err := v.MarshalInt64(test.i, bs)
+			r.NoError(err)
+			r.Equal(test.n, n)
+			r.Equal(test.n, v.SizeInt64(test.i))
+			i2, n, err := v.UnmarshalInt64(bs)
+			r.NoError(err)
+			r.Equal(test.n, n)
+			r.Equal(test.i, i2)
+		}
+	})
+	t.Run("uint", func(t *testing.T) {
+		var tests = []struct {
+			i uint64
+			n int
+		}{
+			{0, 1},
+			{127, 1},
+			{128, 2},
+			{math.MaxUint32, 5},
+			{math.MaxUint64, 10},
+		}
+		for _, test := range tests {
+			bs := make([]byte, 10)
+			n, err := v.MarshalUint64(test.i, bs)
+			r.NoError(err)
+			r.Equal(test.n, n)
+			r.Equal(test.n, v.SizeUint64(test.i))
+			i2, n, err := v.UnmarshalUint64(bs)
+			r.NoError(err)
+			r.Equal(test.n, n)
+			r.Equal(test.i, i2)
+		}
+	})
+	t.Run("should return ErrNotEnoughSpace if there is no space in bs", func(t *testing.T) {
+		bs := make([]byte, 2)
+		n, err := v.MarshalUint32(math.MaxUint32, bs)
+		r.ErrorIs(err, ErrNotEnoughSpace)
+		r.Equal(0, n)
+		_, n, err = v.UnmarshalUint32([]byte{0xff, 0xff})
+		r.ErrorIs(err, ErrNotEnoughSpace)
+		r.Equal(0, n)
+		_, n, err = v.UnmarshalString([]byte{5, 'a'})
+		r.ErrorIs(err, ErrNotEnoughSpace)
+		r.Equal(0, n)
+	})
+	t.Run("should return ErrOverflow if the value does not fit", func(t *testing.T) {
+		bs := make([]byte, 10)
+		_, err := v.MarshalUint32(math.MaxUint16+1, bs)
+		r.NoError(err)
+		_, n, err := v.UnmarshalUint16(bs)
+		r.ErrorIs(err, ErrOverflow)
+		r.Equal(0, n)
+		_, err = v.MarshalInt64(math.MinInt32-1, bs)
+		r.NoError(err)
+		_, _, err = v.UnmarshalInt32(bs)
+		r.ErrorIs(err, ErrOverflow)
+	})
+	t.Run("string", func(t *testing.T) {
+		s := "hello world"
+		bs := make([]byte, 100)
+		n, err := v.MarshalString(s, bs)
+		r.NoError(err)
+		r.Equal(12, n)
+		r.Equal(12, v.SizeString(s))
+		s2, n, err := v.UnmarshalString(bs)
+		r.NoError(err)
+		r.Equal(12, n)
+		r.Equal(s, s2)
+	})
+	t.Run("bytes", func(t *testing.T) {
+		bs := []byte("hello world")
+		bs2 := make([]byte, 100)
+		n, err := v.MarshalBytes(bs, bs2)
+		r.NoError(err)
+		r.Equal(12, n)
+		r.Equal(12, v.SizeBytes(bs))
+		bs3, n, err := v.UnmarshalBytes(bs2)
+		r.NoError(err)
+		r.Equal(12, n)
+		r.Equal(bs, bs3)
+	})
+	t.Run("float64", func(t *testing.T) {
+		bs := make([]byte, 8)
+		n, err := v.MarshalFloat64(math.Pi, bs)
+		r.NoError(err)
+		r.Equal(8, n)
+		f, n, err := v.UnmarshalFloat64(bs)
+		r.NoError(err)
+		r.Equal(8, n)
+		r.Equal(math.Pi, f)
+	})
+}
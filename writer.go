@@ -0,0 +1,222 @@
+package gobin
+
+import "time"
+
+// maxPrefixSize is the most bytes any codec needs for a fixed width value or
+// the length prefix of a string or bytes.
+const maxPrefixSize = 16
+
+// Writer encodes consecutive values, appending them to a Buffer or a
+// SegmentedBuffer. Like Reader it keeps the first error, so a sequence of
+// writes only needs to be checked once at the end.
+type Writer struct {
+	m     Marshaler
+	buf   *Buffer // the chunk of seg being written if seg is set
+	seg   *SegmentedBuffer
+	start int
+	err   error
+}
+
+// NewWriter returns a Writer encoding with m and appending to buf.
+func NewWriter(m Marshaler, buf *Buffer) *Writer {
+	return &Writer{m: m, buf: buf, start: len(buf.Bytes)}
+}
+
+// NewSegmentedWriter returns a Writer encoding with m and appending to seg.
+// Byte slices of at least SegmentRefThreshold bytes are referenced by seg
+// rather than copied, with the codecs of the package.
+func NewSegmentedWriter(m Marshaler, seg *SegmentedBuffer) *Writer {
+	return &Writer{m: m, seg: seg, start: seg.Len()}
+}
+
+// Offset returns the number of bytes written so far.
+func (w *Writer) Offset() int {
+	if w.seg != nil {
+		return w.seg.Len() - w.start
+	}
+	return len(w.buf.Bytes) - w.start
+}
+
+// Err returns the first error encountered.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// available returns the unused capacity of the buffer, growing it to hold at
+// least n more bytes.
+func (w *Writer) available(n int) []byte {
+	if w.seg != nil {
+		w.buf = w.seg.reserve(n)
+	} else {
+		w.buf.Grow(n)
+	}
+	return w.buf.Bytes[len(w.buf.Bytes):cap(w.buf.Bytes)]
+}
+
+func (w *Writer) advance(n int, err error) {
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.buf.Bytes = w.buf.Bytes[:len(w.buf.Bytes)+n]
+}
+
+// WriteLen writes the length prefix of a slice or map.
+func (w *Writer) WriteLen(l int) {
+	w.WriteInt(l)
+}
+
+// WriteFunc encodes a value of the given size with fn, e.g. the MarshalTo
+// method of a nested type.
+func (w *Writer) WriteFunc(size int, fn func([]byte) (int, error)) {
+	if w.err != nil {
+		return
+	}
+	w.advance(fn(w.available(size)[:size]))
+}
+
+// WritePacked writes the n low bits of bits as packed bools.
+func (w *Writer) WritePacked(bits uint64, n int) {
+	if w.err != nil {
+		return
+	}
+	w.advance(MarshalPacked(bits, n, w.available(PackedSize(n))))
+}
+
+func (w *Writer) WriteBool(v bool) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalBool(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteInt(v int) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalInt(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteInt8(v int8) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalInt8(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteInt16(v int16) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalInt16(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteInt32(v int32) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalInt32(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteInt64(v int64) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalInt64(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteUint(v uint) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalUint(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteUint8(v uint8) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalUint8(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteUint16(v uint16) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalUint16(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteUint32(v uint32) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalUint32(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteUint64(v uint64) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalUint64(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteFloat32(v float32) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalFloat32(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteFloat64(v float64) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalFloat64(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteTime(v time.Time) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalTime(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteDuration(v time.Duration) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalDuration(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteGUID(v GUID) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalGUID(v, w.available(maxPrefixSize)))
+}
+
+func (w *Writer) WriteString(v string) {
+	if w.err != nil {
+		return
+	}
+	w.advance(w.m.MarshalString(v, w.available(maxPrefixSize+len(v))))
+}
+
+// bytesPrefixer is implemented by codecs able to encode the prefix of a
+// []byte apart from its contents.
+type bytesPrefixer interface {
+	appendBytesPrefix(dst []byte, v []byte) []byte
+}
+
+func (w *Writer) WriteBytes(v []byte) {
+	if w.err != nil {
+		return
+	}
+	if p, ok := w.m.(bytesPrefixer); ok && w.seg != nil && len(v) >= SegmentRefThreshold {
+		w.buf = w.seg.reserve(maxPrefixSize)
+		w.buf.Bytes = p.appendBytesPrefix(w.buf.Bytes, v)
+		w.seg.WriteRef(v)
+		return
+	}
+	w.advance(w.m.MarshalBytes(v, w.available(maxPrefixSize+len(v))))
+}
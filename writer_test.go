@@ -0,0 +1,44 @@
+package gobin
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	r := require.New(t)
+	t.Run("should append to the buffer", func(t *testing.T) {
+		buf := Buffer{Bytes: []byte{0xff}}
+		w := NewWriter(BigEndian{}, &buf)
+		w.WriteUint16(0x0102)
+		w.WriteUint32(0x03040506)
+		r.NoError(w.Err())
+		r.Equal(6, w.Offset())
+		r.Equal([]byte{0xff, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, buf.Bytes)
+	})
+	t.Run("should grow the buffer", func(t *testing.T) {
+		var buf Buffer
+		w := NewWriter(Varint{}, &buf)
+		s := strings.Repeat("a", 1000)
+		for i := 0; i < 10; i++ {
+			w.WriteString(s)
+		}
+		r.NoError(w.Err())
+		r.Equal(10*Varint{}.SizeString(s), len(buf.Bytes))
+	})
+	t.Run("should keep the first error", func(t *testing.T) {
+		var buf Buffer
+		w := NewWriter(Safe{}, &buf)
+		w.WriteUint8(1)
+		errFail := errors.New("fail")
+		w.WriteFunc(4, func([]byte) (int, error) {
+			return 0, errFail
+		})
+		w.WriteUint8(2)
+		r.ErrorIs(w.Err(), errFail)
+		r.Equal(1, w.Offset())
+	})
+}